@@ -0,0 +1,48 @@
+package httperr
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// ErrUnauthorized is wrapped by the error returned from [BasicAuth] when the
+// request isn't authorized.
+var ErrUnauthorized = errors.New("unauthorized")
+
+// BasicAuth returns a [Middleware] that requires HTTP basic authentication.
+// Requests without credentials, or with credentials that validate rejects,
+// get a 401 error wrapping [ErrUnauthorized] with the WWW-Authenticate header
+// set for realm.
+func BasicAuth(realm string, validate func(user, pass string) bool) Middleware {
+	// The realm is a quoted-string, so backslashes and quotes are escaped as
+	// quoted-pairs.
+	quoted := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(realm)
+	challenge := `Basic realm="` + quoted + `", charset="UTF-8"`
+
+	return Named("httperr.BasicAuth", func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			user, pass, ok := r.BasicAuth()
+			if !ok || !validate(user, pass) {
+				return NewError(
+					ErrUnauthorized,
+					http.StatusUnauthorized,
+					WithHeader("WWW-Authenticate", challenge),
+				)
+			}
+
+			return next.ServeHTTP(w, r)
+		})
+	})
+}
+
+// BasicAuthCredentials returns a validate function for [BasicAuth] that
+// accepts a single user and password, compared in constant time.
+func BasicAuthCredentials(user, pass string) func(user, pass string) bool {
+	return func(u, p string) bool {
+		userOK := subtle.ConstantTimeCompare([]byte(u), []byte(user))
+		passOK := subtle.ConstantTimeCompare([]byte(p), []byte(pass))
+		return userOK&passOK == 1
+	}
+}
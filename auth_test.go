@@ -0,0 +1,25 @@
+package httperr
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBasicAuthRealmEscaped(t *testing.T) {
+	h := HandleErr(io.Discard, nil)(BasicAuth(`a "b" c\`, BasicAuthCredentials("user", "pass"))(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return nil
+	})))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+	want := `Basic realm="a \"b\" c\\", charset="UTF-8"`
+	if got := rec.Header().Get("WWW-Authenticate"); got != want {
+		t.Errorf("WWW-Authenticate = %q, want %q", got, want)
+	}
+}
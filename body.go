@@ -0,0 +1,28 @@
+package httperr
+
+import (
+	"errors"
+	"net/http"
+)
+
+// MaxBodySize returns a [Middleware] that limits the request body to n bytes
+// with [http.MaxBytesReader]. Reading past the limit fails with an
+// [*http.MaxBytesError], which handlers should return, wrapped or not. If the
+// returned error carries no status of its own, it is turned into a 413.
+func MaxBodySize(n int64) Middleware {
+	return Named("httperr.MaxBodySize", func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			r.Body = http.MaxBytesReader(w, r.Body, n)
+			err := next.ServeHTTP(w, r)
+
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				if _, ok := StatusOf(err); !ok {
+					return NewError(err, http.StatusRequestEntityTooLarge)
+				}
+			}
+
+			return err
+		})
+	})
+}
@@ -0,0 +1,36 @@
+package httperr
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMaxBodySize(t *testing.T) {
+	h := HandleErr(io.Discard, nil)(MaxBodySize(8)(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		if _, err := io.ReadAll(r.Body); err != nil {
+			return fmt.Errorf("reading body: %w", err)
+		}
+		return nil
+	})))
+
+	tests := []struct {
+		body string
+		want int
+	}{
+		{body: "small", want: http.StatusOK},
+		{body: strings.Repeat("x", 9), want: http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body)))
+
+		if rec.Code != tt.want {
+			t.Errorf("body of %d bytes: status = %d, want %d", len(tt.body), rec.Code, tt.want)
+		}
+	}
+}
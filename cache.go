@@ -0,0 +1,32 @@
+package httperr
+
+import (
+	"net/http"
+)
+
+// CacheControl returns a [Middleware] that sets the Cache-Control header of
+// the response to directive, such as "public, max-age=300", before calling the
+// next [Handler]. If the next [Handler] returns an error before writing the
+// response, the header is replaced with "no-store", so that error responses
+// aren't cached in place of the page.
+func CacheControl(directive string) Middleware {
+	return Named("httperr.CacheControl", func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			rw := NewResponseWriter(w)
+			rw.Header().Set("Cache-Control", directive)
+
+			err := next.ServeHTTP(rw, r)
+			if err != nil && !rw.Written() {
+				rw.Header().Set("Cache-Control", "no-store")
+			}
+
+			return err
+		})
+	})
+}
+
+// NoCache returns a [Middleware] that sets the Cache-Control header to forbid
+// caching of the response by browsers and proxies alike.
+func NoCache() Middleware {
+	return Named("httperr.NoCache", CacheControl("no-store, no-cache, must-revalidate"))
+}
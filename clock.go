@@ -0,0 +1,13 @@
+package httperr
+
+import "time"
+
+// now returns the current time for the time dependent middleware, such as
+// [Logger], [Metrics], and [TokenBucket]. Tests in this package replace it with
+// a fake clock instead of sleeping.
+var now = time.Now
+
+// since returns the time elapsed since t, as measured by now.
+func since(t time.Time) time.Duration {
+	return now().Sub(t)
+}
@@ -0,0 +1,24 @@
+package httperr
+
+import (
+	"testing"
+	"time"
+)
+
+// fakeClock is a clock for the tests that only moves when advanced.
+type fakeClock struct {
+	t time.Time
+}
+
+// setFakeClock replaces now with a fake clock until the end of the test. Since
+// now is global, tests that call it must not run in parallel.
+func setFakeClock(t *testing.T) *fakeClock {
+	c := &fakeClock{t: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	now = func() time.Time { return c.t }
+	t.Cleanup(func() { now = time.Now })
+	return c
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.t = c.t.Add(d)
+}
@@ -0,0 +1,247 @@
+package httperr
+
+import (
+	"compress/gzip"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// minCompressSize is the smallest response body that is compressed.
+const minCompressSize = 1024
+
+// Compress returns a [Middleware] that gzip compresses responses for clients
+// that accept it, at the given [gzip] compression level. Bodies smaller than
+// 1KiB, responses that already have a Content-Encoding, and content types
+// that are already compressed, such as images and archives, are sent as is.
+//
+// If the next [Handler] returns an error before writing anything, the error
+// is returned untouched and the error body written by the [ToStd] is not
+// compressed, since it runs after this middleware has returned. Use
+// [CompressToStd] to compress error bodies too.
+func Compress(level int) Middleware {
+	level = gzipLevel(level)
+
+	return Named("httperr.Compress", func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			w.Header().Add("Vary", "Accept-Encoding")
+			if !acceptsGzip(r) {
+				return next.ServeHTTP(w, r)
+			}
+
+			gw := &gzipWriter{w: w, level: level}
+			err := next.ServeHTTP(gw, r)
+			if err != nil && !gw.started() {
+				return err
+			}
+
+			gw.Close()
+			return err
+		})
+	})
+}
+
+// CompressToStd returns a [ToStd] that compresses the output of toStd like
+// [Compress], including the error responses it writes.
+func CompressToStd(toStd ToStd, level int) ToStd {
+	level = gzipLevel(level)
+
+	return func(h Handler) http.Handler {
+		std := toStd(h)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Accept-Encoding")
+			if !acceptsGzip(r) {
+				std.ServeHTTP(w, r)
+				return
+			}
+
+			gw := &gzipWriter{w: w, level: level}
+			std.ServeHTTP(gw, r)
+			gw.Close()
+		})
+	}
+}
+
+func gzipLevel(level int) int {
+	if level < gzip.HuffmanOnly || level > gzip.BestCompression {
+		return gzip.DefaultCompression
+	}
+	return level
+}
+
+// acceptsGzip reports whether the Accept-Encoding header of r lists gzip
+// without refusing it with a q value of zero, such as "gzip;q=0.0".
+func acceptsGzip(r *http.Request) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if strings.TrimSpace(coding) != "gzip" {
+			continue
+		}
+
+		for _, param := range strings.Split(params, ";") {
+			key, value, _ := strings.Cut(param, "=")
+			if !strings.EqualFold(strings.TrimSpace(key), "q") {
+				continue
+			}
+			if q, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil && q <= 0 {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// compressible reports whether a body of the content type benefits from
+// compression.
+func compressible(contentType string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.TrimSpace(strings.ToLower(mediaType))
+	switch {
+	case mediaType == "image/svg+xml":
+		return true
+	case strings.HasPrefix(mediaType, "image/"),
+		strings.HasPrefix(mediaType, "video/"),
+		strings.HasPrefix(mediaType, "audio/"),
+		strings.HasPrefix(mediaType, "font/woff"):
+		return false
+	}
+
+	switch mediaType {
+	case "application/zip", "application/gzip", "application/x-gzip",
+		"application/zstd", "application/x-bzip2", "application/x-7z-compressed",
+		"application/octet-stream", "text/event-stream":
+		return false
+	}
+	return true
+}
+
+// gzipWriter buffers the start of the body until it can decide whether to
+// compress the response, and then writes the header.
+type gzipWriter struct {
+	w       http.ResponseWriter
+	level   int
+	status  int
+	buf     []byte
+	gz      *gzip.Writer
+	decided bool
+}
+
+func (gw *gzipWriter) Header() http.Header {
+	return gw.w.Header()
+}
+
+func (gw *gzipWriter) WriteHeader(code int) {
+	if gw.decided {
+		return
+	}
+
+	if code < 200 && code != http.StatusSwitchingProtocols {
+		gw.w.WriteHeader(code)
+		return
+	}
+
+	if gw.status == 0 {
+		gw.status = code
+	}
+
+	if !bodyAllowed(code) {
+		gw.decide()
+	}
+}
+
+func (gw *gzipWriter) Write(b []byte) (int, error) {
+	if gw.status == 0 {
+		gw.status = http.StatusOK
+	}
+
+	if gw.decided {
+		if gw.gz != nil {
+			return gw.gz.Write(b)
+		}
+		return gw.w.Write(b)
+	}
+
+	gw.buf = append(gw.buf, b...)
+	if len(gw.buf) >= minCompressSize {
+		if err := gw.decide(); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+func (gw *gzipWriter) Flush() {
+	if !gw.decided {
+		if gw.status == 0 {
+			gw.status = http.StatusOK
+		}
+		gw.decide()
+	}
+
+	if gw.gz != nil {
+		gw.gz.Flush()
+	}
+	http.NewResponseController(gw.w).Flush()
+}
+
+// Unwrap returns the underlying [http.ResponseWriter] for use with
+// [http.ResponseController].
+func (gw *gzipWriter) Unwrap() http.ResponseWriter {
+	return gw.w
+}
+
+func (gw *gzipWriter) started() bool {
+	return gw.status != 0 || len(gw.buf) > 0
+}
+
+// decide writes the header, compressing the response if the buffered body is
+// large enough and of a compressible type, followed by the buffered body.
+func (gw *gzipWriter) decide() error {
+	gw.decided = true
+	h := gw.w.Header()
+	if h.Get("Content-Type") == "" && len(gw.buf) > 0 {
+		h.Set("Content-Type", http.DetectContentType(gw.buf))
+	}
+
+	if len(gw.buf) >= minCompressSize &&
+		bodyAllowed(gw.status) &&
+		h.Get("Content-Encoding") == "" &&
+		compressible(h.Get("Content-Type")) {
+		h.Del("Content-Length")
+		h.Set("Content-Encoding", "gzip")
+		gw.gz, _ = gzip.NewWriterLevel(gw.w, gw.level)
+	}
+
+	gw.w.WriteHeader(gw.status)
+	buf := gw.buf
+	gw.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+
+	var err error
+	if gw.gz != nil {
+		_, err = gw.gz.Write(buf)
+	} else {
+		_, err = gw.w.Write(buf)
+	}
+	return err
+}
+
+// Close writes anything still buffered and completes the compressed stream.
+func (gw *gzipWriter) Close() error {
+	if !gw.decided {
+		if !gw.started() {
+			return nil
+		}
+		if err := gw.decide(); err != nil {
+			return err
+		}
+	}
+
+	if gw.gz != nil {
+		return gw.gz.Close()
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package httperr
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// serveGzip serves a request accepting gzip with h, and returns the response
+// with its body decompressed if it was compressed.
+func serveGzip(t *testing.T, h http.Handler) (*httptest.ResponseRecorder, string) {
+	t.Helper()
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, r)
+
+	if rec.Header().Get("Content-Encoding") != "gzip" {
+		return rec, rec.Body.String()
+	}
+
+	zr, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rec, string(body)
+}
+
+func compressed(contentType, body string) http.Handler {
+	return HandleErr(io.Discard, nil)(Compress(gzip.BestSpeed)(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		if contentType != "" {
+			w.Header().Set("Content-Type", contentType)
+		}
+		io.WriteString(w, body)
+		return nil
+	})))
+}
+
+func TestCompressThreshold(t *testing.T) {
+	for _, size := range []int{minCompressSize - 1, minCompressSize, 4 * minCompressSize} {
+		want := strings.Repeat("a", size)
+		rec, body := serveGzip(t, compressed("text/plain", want))
+
+		wantGzip := size >= minCompressSize
+		if got := rec.Header().Get("Content-Encoding") == "gzip"; got != wantGzip {
+			t.Errorf("size %d: compressed = %t, want %t", size, got, wantGzip)
+		}
+		if body != want {
+			t.Errorf("size %d: body of %d bytes, want %d", size, len(body), size)
+		}
+		if got := rec.Header().Get("Vary"); got != "Accept-Encoding" {
+			t.Errorf("size %d: Vary = %q, want %q", size, got, "Accept-Encoding")
+		}
+	}
+}
+
+func TestCompressSkipped(t *testing.T) {
+	body := strings.Repeat("a", 2*minCompressSize)
+
+	rec, _ := serveGzip(t, compressed("image/png", body))
+	if got := rec.Header().Get("Content-Encoding"); got != "" {
+		t.Errorf("image/png: Content-Encoding = %q, want none", got)
+	}
+
+	h := HandleErr(io.Discard, nil)(Compress(gzip.BestSpeed)(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-Encoding", "br")
+		io.WriteString(w, body)
+		return nil
+	})))
+	rec, got := serveGzip(t, h)
+	if enc := rec.Header().Get("Content-Encoding"); enc != "br" || got != body {
+		t.Errorf("encoded: Content-Encoding = %q with a body of %d bytes, want %q with %d", enc, len(got), "br", len(body))
+	}
+}
+
+func TestCompressNoBody(t *testing.T) {
+	for _, status := range []int{http.StatusNoContent, http.StatusNotModified} {
+		h := HandleErr(io.Discard, nil)(Compress(gzip.BestSpeed)(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			w.WriteHeader(status)
+			return nil
+		})))
+		rec, _ := serveGzip(t, h)
+
+		if rec.Code != status {
+			t.Errorf("status = %d, want %d", rec.Code, status)
+		}
+		if got := rec.Header().Get("Content-Encoding"); got != "" || rec.Body.Len() != 0 {
+			t.Errorf("%d: Content-Encoding = %q, body = %q, want neither", status, got, rec.Body.String())
+		}
+	}
+}
+
+func TestCompressError(t *testing.T) {
+	msg := strings.Repeat("a", 2*minCompressSize)
+	next := HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return NewError(nil, http.StatusBadRequest, WithMessage(msg))
+	})
+
+	// The error body is written by HandleErr after Compress has returned.
+	rec, body := serveGzip(t, HandleErr(io.Discard, nil)(Compress(gzip.BestSpeed)(next)))
+	if got := rec.Header().Get("Content-Encoding"); got != "" {
+		t.Errorf("Compress: Content-Encoding = %q, want none", got)
+	}
+	if rec.Code != http.StatusBadRequest || body != msg+"\n" {
+		t.Errorf("Compress: response = %d with %d bytes, want %d with %d", rec.Code, len(body), http.StatusBadRequest, len(msg)+1)
+	}
+
+	rec, body = serveGzip(t, CompressToStd(HandleErr(io.Discard, nil), gzip.BestSpeed)(next))
+	if got := rec.Header().Get("Content-Encoding"); got != "gzip" {
+		t.Errorf("CompressToStd: Content-Encoding = %q, want gzip", got)
+	}
+	if rec.Code != http.StatusBadRequest || body != msg+"\n" {
+		t.Errorf("CompressToStd: response = %d with %d bytes, want %d with %d", rec.Code, len(body), http.StatusBadRequest, len(msg)+1)
+	}
+}
+
+func TestAcceptsGzip(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   bool
+	}{
+		{"", false},
+		{"gzip", true},
+		{"br, gzip", true},
+		{"deflate", false},
+		{"gzip;q=0.5", true},
+		{"gzip;q=0", false},
+		{"gzip; q=0", false},
+		{"gzip;q=0.0", false},
+		{"gzip;q=0.000", false},
+		{"gzip;Q=0", false},
+	}
+
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Accept-Encoding", tt.accept)
+		if got := acceptsGzip(r); got != tt.want {
+			t.Errorf("acceptsGzip(%q) = %t, want %t", tt.accept, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package httperr
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// ErrServerBusy is wrapped by the error returned from [LimitConcurrency] when
+// a request can't be served because the limit is reached.
+var ErrServerBusy = errors.New("server busy")
+
+// LimitConcurrency returns a [Middleware] that serves at most n requests at a
+// time. A request over the limit waits up to wait for another to finish, or
+// fails immediately if wait is zero. Requests that can't be served get a 503
+// error wrapping [ErrServerBusy], with the Retry-After header set. A request
+// whose context is done while waiting returns the context's error. It panics
+// if n is less than one, since no request could ever be served.
+func LimitConcurrency(n int, wait time.Duration) Middleware {
+	if n < 1 {
+		panic(fmt.Sprintf("httperr: LimitConcurrency limit must be at least 1, got %d", n))
+	}
+
+	sem := make(chan struct{}, n)
+
+	return Named("httperr.LimitConcurrency", func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			if !acquire(r, sem, wait) {
+				if err := r.Context().Err(); err != nil {
+					return err
+				}
+
+				return NewError(
+					ErrServerBusy,
+					http.StatusServiceUnavailable,
+					WithMessage("server busy"),
+					WithRetryAfter(max(wait, time.Second)),
+				)
+			}
+			defer func() { <-sem }()
+
+			return next.ServeHTTP(w, r)
+		})
+	})
+}
+
+// acquire takes a slot of sem for r, waiting up to wait, and reports whether
+// it did.
+func acquire(r *http.Request, sem chan struct{}, wait time.Duration) bool {
+	select {
+	case sem <- struct{}{}:
+		return true
+	default:
+	}
+
+	if wait <= 0 {
+		return false
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case sem <- struct{}{}:
+		return true
+	case <-timer.C:
+		return false
+	case <-r.Context().Done():
+		return false
+	}
+}
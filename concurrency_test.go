@@ -0,0 +1,20 @@
+package httperr
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLimitConcurrencyInvalidLimit(t *testing.T) {
+	for _, n := range []int{0, -1} {
+		func() {
+			defer func() {
+				v, _ := recover().(string)
+				if !strings.Contains(v, "LimitConcurrency limit must be at least 1") {
+					t.Errorf("LimitConcurrency(%d) panicked with %q", n, v)
+				}
+			}()
+			LimitConcurrency(n, 0)
+		}()
+	}
+}
@@ -0,0 +1,108 @@
+package httperr
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrOriginNotAllowed is wrapped by the error returned from [CORS] for a
+// preflight request from an origin that isn't allowed.
+var ErrOriginNotAllowed = errors.New("origin not allowed")
+
+// CORSOptions configures the [CORS] middleware.
+type CORSOptions struct {
+	// AllowedOrigins is the list of origins allowed to make requests. "*"
+	// allows any origin.
+	AllowedOrigins []string
+
+	// AllowedMethods is the list of methods allowed in preflight requests. It
+	// defaults to GET, HEAD and POST.
+	AllowedMethods []string
+
+	// AllowedHeaders is the list of request headers allowed in preflight
+	// requests.
+	AllowedHeaders []string
+
+	// AllowCredentials allows requests with credentials. It can't be used with
+	// an AllowedOrigins of "*".
+	AllowCredentials bool
+
+	// MaxAge is how long the result of a preflight request may be cached.
+	MaxAge time.Duration
+}
+
+// CORS returns a [Middleware] that sets the Access-Control-* headers for
+// requests from allowed origins. Preflight OPTIONS requests are answered with
+// a 204, or a 403 error wrapping [ErrOriginNotAllowed] for origins that aren't
+// allowed, without calling the next [Handler].
+//
+// Combining AllowCredentials with a wildcard origin is a misconfiguration that
+// results in a 500 error for every cross-origin request.
+func CORS(opts CORSOptions) Middleware {
+	allowAll := slices.Contains(opts.AllowedOrigins, "*")
+	methods := opts.AllowedMethods
+	if len(methods) == 0 {
+		methods = []string{http.MethodGet, http.MethodHead, http.MethodPost}
+	}
+
+	allowMethods := strings.Join(methods, ", ")
+	allowHeaders := strings.Join(opts.AllowedHeaders, ", ")
+	maxAge := strconv.Itoa(int(opts.MaxAge.Seconds()))
+
+	return Named("httperr.CORS", func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			origin := r.Header.Get("Origin")
+			if origin == "" {
+				return next.ServeHTTP(w, r)
+			}
+
+			if allowAll && opts.AllowCredentials {
+				return NewError(
+					errors.New("cors: AllowCredentials can't be used with a wildcard origin"),
+					http.StatusInternalServerError,
+				)
+			}
+
+			h := w.Header()
+			h.Add("Vary", "Origin")
+			preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+			allowed := allowAll || slices.Contains(opts.AllowedOrigins, origin)
+			if !allowed {
+				if preflight {
+					return NewError(ErrOriginNotAllowed, http.StatusForbidden, WithMessage("origin not allowed"))
+				}
+				return next.ServeHTTP(w, r)
+			}
+
+			if allowAll {
+				h.Set("Access-Control-Allow-Origin", "*")
+			} else {
+				h.Set("Access-Control-Allow-Origin", origin)
+			}
+			if opts.AllowCredentials {
+				h.Set("Access-Control-Allow-Credentials", "true")
+			}
+
+			if !preflight {
+				return next.ServeHTTP(w, r)
+			}
+
+			h.Add("Vary", "Access-Control-Request-Method")
+			h.Add("Vary", "Access-Control-Request-Headers")
+			h.Set("Access-Control-Allow-Methods", allowMethods)
+			if allowHeaders != "" {
+				h.Set("Access-Control-Allow-Headers", allowHeaders)
+			}
+			if opts.MaxAge > 0 {
+				h.Set("Access-Control-Max-Age", maxAge)
+			}
+
+			w.WriteHeader(http.StatusNoContent)
+			return nil
+		})
+	})
+}
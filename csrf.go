@@ -0,0 +1,145 @@
+package httperr
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// ErrInvalidCSRFToken is wrapped by the error returned from [CSRF] when an
+// unsafe request has a missing or mismatched token.
+var ErrInvalidCSRFToken = errors.New("invalid CSRF token")
+
+// CSRFOptions configures the [CSRF] middleware.
+type CSRFOptions struct {
+	// SessionToken returns the token kept in the server side session of r,
+	// for the synchronizer token pattern. When it is nil the double submit
+	// cookie pattern is used instead, and the token is issued in a cookie.
+	SessionToken func(r *http.Request) string
+
+	// CookieName is the name of the cookie holding the token for the double
+	// submit cookie pattern. It defaults to "csrf_token".
+	CookieName string
+
+	// CookiePath is the path of the token cookie. It defaults to "/".
+	CookiePath string
+
+	// CookieSecure restricts the token cookie to HTTPS.
+	CookieSecure bool
+
+	// CookieSameSite is the SameSite attribute of the token cookie. It
+	// defaults to [http.SameSiteLaxMode].
+	CookieSameSite http.SameSite
+
+	// CookieMaxAge is how long the token cookie lasts. It defaults to a
+	// session cookie.
+	CookieMaxAge time.Duration
+
+	// HeaderName is the request header checked for the submitted token. It
+	// defaults to "X-CSRF-Token".
+	HeaderName string
+
+	// FieldName is the form field checked for the submitted token when the
+	// header is missing. It defaults to "csrf_token".
+	FieldName string
+}
+
+type csrfTokenKey struct{}
+
+// CSRFTokenFromContext returns the token stored by the [CSRF] middleware, for
+// including in forms and templates, or an empty string if there is none.
+func CSRFTokenFromContext(ctx context.Context) string {
+	token, _ := ctx.Value(csrfTokenKey{}).(string)
+	return token
+}
+
+// CSRF returns a [Middleware] that protects against cross-site request
+// forgery. Every request has its token stored in the request context,
+// retrievable with [CSRFTokenFromContext]. With the double submit cookie
+// pattern a new token is issued in a cookie on safe requests (GET, HEAD,
+// OPTIONS and TRACE) that don't have one.
+//
+// Unsafe requests must submit the token in the HeaderName header or the
+// FieldName form field, or they get a 403 error wrapping
+// [ErrInvalidCSRFToken]. Tokens are compared in constant time.
+func CSRF(opts CSRFOptions) Middleware {
+	if opts.CookieName == "" {
+		opts.CookieName = "csrf_token"
+	}
+	if opts.CookiePath == "" {
+		opts.CookiePath = "/"
+	}
+	if opts.CookieSameSite == 0 {
+		opts.CookieSameSite = http.SameSiteLaxMode
+	}
+	if opts.HeaderName == "" {
+		opts.HeaderName = "X-CSRF-Token"
+	}
+	if opts.FieldName == "" {
+		opts.FieldName = "csrf_token"
+	}
+
+	return Named("httperr.CSRF", func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			token := opts.expectedToken(r)
+
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+				if token == "" && opts.SessionToken == nil {
+					token = newCSRFToken()
+					http.SetCookie(w, opts.cookie(token))
+				}
+			default:
+				submitted := r.Header.Get(opts.HeaderName)
+				if submitted == "" {
+					submitted = r.PostFormValue(opts.FieldName)
+				}
+
+				if token == "" || subtle.ConstantTimeCompare([]byte(submitted), []byte(token)) != 1 {
+					return NewError(ErrInvalidCSRFToken, http.StatusForbidden, WithMessage("invalid CSRF token"))
+				}
+			}
+
+			w.Header().Add("Vary", "Cookie")
+			ctx := context.WithValue(r.Context(), csrfTokenKey{}, token)
+			return next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	})
+}
+
+// expectedToken returns the token that r must submit, or an empty string if
+// it has none.
+func (opts CSRFOptions) expectedToken(r *http.Request) string {
+	if opts.SessionToken != nil {
+		return opts.SessionToken(r)
+	}
+
+	cookie, err := r.Cookie(opts.CookieName)
+	if err != nil {
+		return ""
+	}
+
+	return cookie.Value
+}
+
+func (opts CSRFOptions) cookie(token string) *http.Cookie {
+	return &http.Cookie{
+		Name:     opts.CookieName,
+		Value:    token,
+		Path:     opts.CookiePath,
+		MaxAge:   int(opts.CookieMaxAge.Seconds()),
+		Secure:   opts.CookieSecure,
+		HttpOnly: true,
+		SameSite: opts.CookieSameSite,
+	}
+}
+
+func newCSRFToken() string {
+	var b [32]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,121 @@
+package httperr
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// csrfNext records the token in the context as the X-Token header.
+var csrfNext = HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("X-Token", CSRFTokenFromContext(r.Context()))
+	return nil
+})
+
+func TestCSRFSafeRequestIssuesCookie(t *testing.T) {
+	rec := httptest.NewRecorder()
+	err := CSRF(CSRFOptions{})(csrfNext).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != "csrf_token" || cookies[0].Value == "" || !cookies[0].HttpOnly {
+		t.Fatalf("cookies = %v, want one HttpOnly csrf_token cookie", cookies)
+	}
+	if got := rec.Header().Get("X-Token"); got != cookies[0].Value {
+		t.Errorf("context token = %q, want %q", got, cookies[0].Value)
+	}
+}
+
+func TestCSRFUnsafeRequest(t *testing.T) {
+	const token = "secret"
+
+	tests := []struct {
+		name   string
+		cookie string
+		header string
+		form   string
+		wantOK bool
+	}{
+		{name: "header", cookie: token, header: token, wantOK: true},
+		{name: "form", cookie: token, form: token, wantOK: true},
+		{name: "missing", cookie: token},
+		{name: "mismatched", cookie: token, header: "other"},
+		{name: "no cookie", header: token},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{}
+			if tt.form != "" {
+				form.Set("csrf_token", tt.form)
+			}
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(form.Encode()))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			if tt.cookie != "" {
+				r.AddCookie(&http.Cookie{Name: "csrf_token", Value: tt.cookie})
+			}
+			if tt.header != "" {
+				r.Header.Set("X-CSRF-Token", tt.header)
+			}
+
+			rec := httptest.NewRecorder()
+			err := CSRF(CSRFOptions{})(csrfNext).ServeHTTP(rec, r)
+			checkCSRF(t, rec, err, tt.wantOK, token)
+		})
+	}
+}
+
+func TestCSRFSessionToken(t *testing.T) {
+	tests := []struct {
+		name    string
+		session string
+		header  string
+		wantOK  bool
+	}{
+		{name: "match", session: "secret", header: "secret", wantOK: true},
+		{name: "mismatched", session: "secret", header: "other"},
+		{name: "empty session", session: "", header: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := CSRFOptions{SessionToken: func(*http.Request) string { return tt.session }}
+			r := httptest.NewRequest(http.MethodPost, "/", nil)
+			r.Header.Set("X-CSRF-Token", tt.header)
+
+			rec := httptest.NewRecorder()
+			err := CSRF(opts)(csrfNext).ServeHTTP(rec, r)
+			checkCSRF(t, rec, err, tt.wantOK, tt.session)
+
+			if len(rec.Result().Cookies()) != 0 {
+				t.Error("cookie issued with a session token")
+			}
+		})
+	}
+}
+
+func checkCSRF(t *testing.T, rec *httptest.ResponseRecorder, err error, wantOK bool, token string) {
+	t.Helper()
+
+	if wantOK {
+		if err != nil {
+			t.Fatalf("err = %v, want nil", err)
+		}
+		if got := rec.Header().Get("X-Token"); got != token {
+			t.Errorf("context token = %q, want %q", got, token)
+		}
+		return
+	}
+
+	if !errors.Is(err, ErrInvalidCSRFToken) {
+		t.Errorf("err = %v, want %v", err, ErrInvalidCSRFToken)
+	}
+	if status, _ := StatusOf(err); status != http.StatusForbidden {
+		t.Errorf("status = %d, want %d", status, http.StatusForbidden)
+	}
+}
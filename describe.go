@@ -0,0 +1,57 @@
+package httperr
+
+import (
+	"net/http"
+	"reflect"
+	"runtime"
+)
+
+// Named returns a [Middleware] that behaves like mw and is described as name by
+// [DescribeChain].
+func Named(name string, mw Middleware) Middleware {
+	return func(next Handler) Handler {
+		return namedHandler{Handler: mw(next), name: name}
+	}
+}
+
+type namedHandler struct {
+	Handler
+	name string
+}
+
+// Named returns the name given to [Named].
+func (h namedHandler) Named() string {
+	return h.name
+}
+
+// DescribeChain returns the names of mw in the order they are invoked on a
+// request by [Wrap], for debugging the order of a chain. Each [Middleware] is
+// applied to a placeholder [Handler] to find its name. A [Handler] returned by
+// a [Middleware] that has a Named() string method, such as one wrapped with
+// [Named], is described by it, otherwise the name of the [Middleware]
+// function is used. The middleware of this package are described by their
+// exported names, such as "httperr.Recover".
+func DescribeChain(mw ...Middleware) []string {
+	probe := HandlerFunc(func(http.ResponseWriter, *http.Request) error { return nil })
+
+	names := make([]string, 0, len(mw))
+	for _, m := range mw {
+		if m == nil {
+			names = append(names, "<nil>")
+			continue
+		}
+
+		if named, ok := m(probe).(interface{ Named() string }); ok {
+			names = append(names, named.Named())
+			continue
+		}
+
+		name := "<unknown>"
+		if fn := runtime.FuncForPC(reflect.ValueOf(m).Pointer()); fn != nil {
+			name = fn.Name()
+		}
+		names = append(names, name)
+	}
+
+	return names
+}
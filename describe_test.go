@@ -0,0 +1,14 @@
+package httperr
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDescribeChainBuiltinNames(t *testing.T) {
+	got := DescribeChain(Recover(), RequestID(), NoCache(), RecoverWith(nil))
+	want := []string{"httperr.Recover", "httperr.RequestID", "httperr.NoCache", "httperr.RecoverWith"}
+	if !slices.Equal(got, want) {
+		t.Errorf("DescribeChain = %q, want %q", got, want)
+	}
+}
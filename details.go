@@ -0,0 +1,78 @@
+package httperr
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+)
+
+// ErrorDetails describes how an error is presented to the client, as built by
+// the function given to [WithErrorDetails].
+type ErrorDetails struct {
+	// Status is the status code of the response. Zero means a 500.
+	Status int
+
+	// Message is the message sent to the client. It defaults to the status
+	// text.
+	Message string
+
+	// Code is an application specific error code, as carried by [Code].
+	Code string
+
+	// Fields are attributes of the error, as attached with [WithField].
+	// They are meant for the logs, but a [DetailsRenderer] may choose to send
+	// some of them.
+	Fields []slog.Attr
+
+	// Header is set on the response, in addition to the headers carried by
+	// the error.
+	Header http.Header
+}
+
+// DetailsRenderer is a function type for writing an error to the client from
+// its [ErrorDetails], for fully custom error envelopes.
+type DetailsRenderer func(w http.ResponseWriter, r *http.Request, details ErrorDetails)
+
+// DetailsOf returns the [ErrorDetails] that err carries through [StatusMsg],
+// [Code], and the headers and fields of [NewError]. Functions given to
+// [WithErrorDetails] can start from it.
+func DetailsOf(err error) ErrorDetails {
+	details := ErrorDetails{
+		Status:  http.StatusInternalServerError,
+		Message: http.StatusText(http.StatusInternalServerError),
+	}
+
+	var statusMsg StatusMsg
+	if errors.As(err, &statusMsg) {
+		details.Status, details.Message = statusMsg.StatusMsg()
+	}
+
+	var code Code
+	if errors.As(err, &code) {
+		details.Code = code.Code()
+	}
+
+	var fielded interface{ Fields() []slog.Attr }
+	if errors.As(err, &fielded) {
+		details.Fields = fielded.Fields()
+	}
+
+	var headers interface{ Headers() http.Header }
+	if errors.As(err, &headers) {
+		details.Header = headers.Headers().Clone()
+	}
+
+	return details
+}
+
+// WithErrorDetails builds the response to each error from the [ErrorDetails]
+// returned by details, in place of the [StatusMsg] of the error and any
+// [WithMapper]. The response is written by render, or by the [ErrFunc] or
+// [ErrRenderer] with the status and message of the details when render is
+// nil. A message from a [Translator] replaces the message of the details.
+func WithErrorDetails(details func(err error) ErrorDetails, render DetailsRenderer) HandleOption {
+	return func(eh *errHandler) {
+		eh.details = details
+		eh.renderDetails = render
+	}
+}
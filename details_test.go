@@ -0,0 +1,35 @@
+package httperr
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWithErrorDetails(t *testing.T) {
+	var calls int
+	details := func(err error) ErrorDetails {
+		calls++
+		d := DetailsOf(err)
+		d.Header = http.Header{"X-Details": {"yes"}}
+		return d
+	}
+
+	h := HandleErr(io.Discard, nil, WithErrorDetails(details, nil))(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return NewError(nil, http.StatusConflict, WithResponseBody([]byte("raw"), "text/plain"))
+	}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if calls != 1 {
+		t.Errorf("details called %d times, want 1", calls)
+	}
+	if got := rec.Header().Get("X-Details"); got != "yes" {
+		t.Errorf("X-Details = %q, want %q", got, "yes")
+	}
+	if rec.Code != http.StatusConflict || rec.Body.String() != "raw" {
+		t.Errorf("response = %d %q, want %d %q", rec.Code, rec.Body.String(), http.StatusConflict, "raw")
+	}
+}
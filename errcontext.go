@@ -0,0 +1,37 @@
+package httperr
+
+import (
+	"context"
+)
+
+type errorSlotKey struct{}
+
+type errorSlot struct {
+	err error
+}
+
+// ContextWithErrorSlot returns a copy of ctx that holds the error returned by
+// a [Handler]. Context values only flow inward, so a layer outside of the
+// [ToStd], such as a stdlib middleware wrapping the converted handler, uses
+// this to see the error: it serves the request with the returned context and
+// then reads the error with [ErrorFromContext].
+func ContextWithErrorSlot(ctx context.Context) context.Context {
+	return context.WithValue(ctx, errorSlotKey{}, &errorSlot{})
+}
+
+// ErrorFromContext returns the error stored by [HandleErr] in a context
+// prepared with [ContextWithErrorSlot], or nil if there is none.
+func ErrorFromContext(ctx context.Context) error {
+	slot, _ := ctx.Value(errorSlotKey{}).(*errorSlot)
+	if slot == nil {
+		return nil
+	}
+
+	return slot.err
+}
+
+func storeError(ctx context.Context, err error) {
+	if slot, _ := ctx.Value(errorSlotKey{}).(*errorSlot); slot != nil {
+		slot.err = err
+	}
+}
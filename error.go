@@ -0,0 +1,427 @@
+package httperr
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrResponseWritten signals that the handler has written the full response
+// itself. An error matching it is logged by [HandleErr], but no response is
+// written for it. Return it wrapped, or use [WithResponseWritten].
+var ErrResponseWritten = errors.New("response already written")
+
+// StatusMsg is satisfied by errors that carry an http status code and a
+// message that is safe to send to the client.
+type StatusMsg interface {
+	StatusMsg() (int, string)
+}
+
+// Code is satisfied by errors that carry an application specific error code,
+// such as "user.not_found", for clients to branch on.
+type Code interface {
+	Code() string
+}
+
+// StatusOf returns the status code of the first error in err's tree that
+// satisfies [StatusMsg], and whether one was found.
+func StatusOf(err error) (int, bool) {
+	var statusMsg StatusMsg
+	if !errors.As(err, &statusMsg) {
+		return 0, false
+	}
+
+	status, _ := statusMsg.StatusMsg()
+	return status, true
+}
+
+// IsClientError reports whether err has a 4xx status, as found by
+// [StatusOf].
+func IsClientError(err error) bool {
+	status, ok := StatusOf(err)
+	return ok && status >= 400 && status <= 499
+}
+
+// IsServerError reports whether err has a 5xx status, as found by [StatusOf].
+// A non-nil error without a status is a server error, matching the 500 that
+// [HandleErr] responds with.
+func IsServerError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	status, ok := StatusOf(err)
+	return !ok || status >= 500 && status <= 599
+}
+
+// IsHandlerError reports whether err's tree contains an error created by this
+// package, such as by [NewError]. Middleware can use it to tell errors that
+// already carry a status from raw errors that still need one. To match any
+// error with a status, including those of other packages, use [StatusOf].
+func IsHandlerError(err error) bool {
+	var h *handlerError
+	return errors.As(err, &h)
+}
+
+type handlerError struct {
+	err         error
+	status      int
+	responseMsg string
+	logMsg      string
+	msgKey      string
+	msgArgs     []any
+	code        string
+	header      http.Header
+	contentType string
+	body        []byte
+	bodyReader  io.Reader
+	stack       []uintptr
+	written     bool
+	fields      []slog.Attr
+}
+
+// Option configures an error created by [NewError].
+type Option func(*handlerError)
+
+// WithMessage sets the message sent to the client.
+func WithMessage(msg string) Option {
+	return func(h *handlerError) {
+		h.responseMsg = msg
+	}
+}
+
+// WithMessages sets the message sent to the client to msg joined with sep.
+func WithMessages(sep string, msg ...string) Option {
+	return WithMessage(strings.Join(msg, sep))
+}
+
+// WithClientMessage sets the message sent to the client. It is the same as
+// [WithMessage].
+func WithClientMessage(msg string) Option {
+	return WithMessage(msg)
+}
+
+// WithLogMessage sets a message that is logged with the error but never sent
+// to the client.
+func WithLogMessage(msg string) Option {
+	return func(h *handlerError) {
+		h.logMsg = msg
+	}
+}
+
+// WithCode sets an application specific error code, which [JSONErrRenderer]
+// includes in the response as "code".
+func WithCode(code string) Option {
+	return func(h *handlerError) {
+		h.code = code
+	}
+}
+
+// WithHeader adds a header to be set on the response when the error is
+// handled.
+func WithHeader(key, value string) Option {
+	return func(h *handlerError) {
+		if h.header == nil {
+			h.header = make(http.Header)
+		}
+		h.header.Add(key, value)
+	}
+}
+
+// WithHeaders adds a set of headers to be set on the response when the error is
+// handled.
+func WithHeaders(header http.Header) Option {
+	return func(h *handlerError) {
+		if h.header == nil {
+			h.header = make(http.Header)
+		}
+		for key, values := range header {
+			for _, value := range values {
+				h.header.Add(key, value)
+			}
+		}
+	}
+}
+
+// WithRetryAfter sets the Retry-After header to d, rounded up to the second.
+func WithRetryAfter(d time.Duration) Option {
+	seconds := int64(math.Ceil(d.Seconds()))
+	if seconds < 0 {
+		seconds = 0
+	}
+
+	return withRetryAfter(strconv.FormatInt(seconds, 10))
+}
+
+// WithRetryAt sets the Retry-After header to the time t.
+func WithRetryAt(t time.Time) Option {
+	return withRetryAfter(t.UTC().Format(http.TimeFormat))
+}
+
+func withRetryAfter(value string) Option {
+	return func(h *handlerError) {
+		if h.header == nil {
+			h.header = make(http.Header)
+		}
+		h.header.Set("Retry-After", value)
+	}
+}
+
+// WithResponseWritten marks the error as matching [ErrResponseWritten], for
+// handlers that have already written the response.
+func WithResponseWritten() Option {
+	return func(h *handlerError) {
+		h.written = true
+	}
+}
+
+// WithField attaches a key and value to the error for logging. Fields are
+// never sent to the client.
+func WithField(key string, value any) Option {
+	return func(h *handlerError) {
+		h.fields = append(h.fields, slog.Any(key, value))
+	}
+}
+
+// WithFields attaches a set of keys and values to the error for logging, in
+// order of their keys. Fields are never sent to the client.
+func WithFields(fields map[string]any) Option {
+	return func(h *handlerError) {
+		for _, key := range slices.Sorted(maps.Keys(fields)) {
+			h.fields = append(h.fields, slog.Any(key, fields[key]))
+		}
+	}
+}
+
+// WithResponseBody sets a raw body and content type to send to the client in
+// place of the message.
+func WithResponseBody(body []byte, contentType string) Option {
+	return func(h *handlerError) {
+		h.body = body
+		h.contentType = contentType
+	}
+}
+
+// WithBodyReader sets a body that is streamed to the client from r, with the
+// content type, in place of the message, for bodies that are large or
+// generated lazily. The status and headers are sent first, so an error
+// reading r is only logged. If r is an [io.Closer] it is closed once the body
+// is sent. The body can only be sent once.
+func WithBodyReader(r io.Reader, contentType string) Option {
+	return func(h *handlerError) {
+		h.bodyReader = r
+		h.contentType = contentType
+	}
+}
+
+// NewError wraps err with an http status code. The error can be further
+// configured by providing a set of [Option]. For a status of 500 or above the
+// stack trace is captured, unless disabled with [SetStackTraces].
+//
+// A nil err is allowed, for responding with a status when there is no
+// underlying cause. The response is built from the status and message as
+// usual. There is then no cause for [errors.Is] or [errors.As] to find, but the
+// returned error itself is still matched, for example as a [StatusMsg] or as
+// [ErrResponseWritten] with [WithResponseWritten].
+func NewError(err error, status int, opts ...Option) error {
+	h := &handlerError{
+		err:    err,
+		status: status,
+	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	if status >= http.StatusInternalServerError && captureStack.Load() {
+		h.stack = callers()
+	}
+
+	return h
+}
+
+// NewJoinError wraps all of errs, as joined by [errors.Join], with an http
+// status code. [errors.Is] and [errors.As] match any of errs.
+func NewJoinError(errs []error, status int, opts ...Option) error {
+	return NewError(errors.Join(errs...), status, opts...)
+}
+
+// WithStatus returns err with its status code replaced. If err was created by
+// [NewError], a copy is returned that keeps its message and wrapped error,
+// otherwise err is wrapped with status as by [NewError].
+func WithStatus(err error, status int) error {
+	h, ok := err.(*handlerError)
+	if !ok {
+		return NewError(err, status)
+	}
+
+	h = h.clone()
+	h.status = status
+	return h
+}
+
+// OverrideStatus returns err with only the status sent to the client replaced,
+// for middleware that degrades a failure to a different status, such as a 500
+// to a 503 when a dependency is down. An error created by [NewError] is
+// copied as by [WithStatus]. It differs from [WithStatus] for other errors:
+// the client message of any [StatusMsg] in the tree of err is kept, where
+// [WithStatus] would fall back to the status text. A nil err returns nil.
+func OverrideStatus(err error, status int) error {
+	if err == nil {
+		return nil
+	}
+
+	if _, ok := err.(*handlerError); ok {
+		return WithStatus(err, status)
+	}
+
+	var opts []Option
+	var statusMsg StatusMsg
+	if errors.As(err, &statusMsg) {
+		_, msg := statusMsg.StatusMsg()
+		opts = append(opts, WithMessage(msg))
+	}
+
+	return NewError(err, status, opts...)
+}
+
+// NewErrorMsg wraps err with an http status code and an optional message for
+// the client. The responseMsg values are joined with a single space, so
+// "line1", "line2" becomes "line1 line2". Use [NewError] with [WithMessages]
+// to choose the separator.
+func NewErrorMsg(err error, status int, responseMsg ...string) error {
+	return NewError(err, status, WithMessage(strings.Join(responseMsg, " ")))
+}
+
+// NewErrorf formats an error like [fmt.Errorf], including %w wrapping, and
+// wraps it with an http status code. The client message is the status text.
+func NewErrorf(status int, format string, args ...any) error {
+	return NewError(fmt.Errorf(format, args...), status)
+}
+
+func (h *handlerError) clone() *handlerError {
+	c := *h
+	c.header = h.header.Clone()
+	return &c
+}
+
+// Error satisfies the error interface. The message is the one sent to the
+// client, as returned by StatusMsg. The wrapped error is omitted when it is
+// nil.
+func (h *handlerError) Error() string {
+	_, msg := h.StatusMsg()
+	var b strings.Builder
+	fmt.Fprintf(&b, "status=%d msg=%q", h.status, msg)
+	if h.logMsg != "" {
+		fmt.Fprintf(&b, " log=%q", h.logMsg)
+	}
+	if h.err != nil {
+		fmt.Fprintf(&b, " err=%q", h.err)
+	}
+	for _, field := range h.fields {
+		fmt.Fprintf(&b, " %s=%q", field.Key, field.Value)
+	}
+	return b.String()
+}
+
+// LogValue satisfies the [slog.LogValuer] interface, so that the fields of the
+// error are logged as a group. Like Error, it logs the message returned by
+// StatusMsg.
+func (h *handlerError) LogValue() slog.Value {
+	_, msg := h.StatusMsg()
+	attrs := []slog.Attr{
+		slog.Int("status", h.status),
+		slog.String("msg", msg),
+	}
+	if h.logMsg != "" {
+		attrs = append(attrs, slog.String("log", h.logMsg))
+	}
+	if h.code != "" {
+		attrs = append(attrs, slog.String("code", h.code))
+	}
+	if h.err != nil {
+		attrs = append(attrs, slog.String("err", h.err.Error()))
+	}
+	if len(h.fields) > 0 {
+		attrs = append(attrs, slog.Attr{Key: "fields", Value: slog.GroupValue(h.fields...)})
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// StatusMsg satisfies the [StatusMsg] interface. The message defaults to the
+// status text when none was provided.
+func (h *handlerError) StatusMsg() (int, string) {
+	if h.responseMsg == "" {
+		return h.status, http.StatusText(h.status)
+	}
+
+	return h.status, h.responseMsg
+}
+
+// Status returns the http status code.
+func (h *handlerError) Status() int {
+	return h.status
+}
+
+// Code satisfies the [Code] interface.
+func (h *handlerError) Code() string {
+	return h.code
+}
+
+// StackTrace returns the program counters of the stack where the error was
+// created, or nil if it wasn't captured.
+func (h *handlerError) StackTrace() []uintptr {
+	return h.stack
+}
+
+// Headers returns the headers to set on the response.
+func (h *handlerError) Headers() http.Header {
+	return h.header
+}
+
+// ResponseBody returns the raw body and content type to send to the client, and
+// whether one was set.
+func (h *handlerError) ResponseBody() (string, []byte, bool) {
+	return h.contentType, h.body, h.body != nil
+}
+
+// ResponseBodyReader returns the body reader and content type to stream to
+// the client, and whether one was set.
+func (h *handlerError) ResponseBodyReader() (string, io.Reader, bool) {
+	return h.contentType, h.bodyReader, h.bodyReader != nil
+}
+
+// Fields returns the fields attached with [WithField] and [WithFields].
+func (h *handlerError) Fields() []slog.Attr {
+	return h.fields
+}
+
+// Unwrap returns the wrapped error.
+func (h *handlerError) Unwrap() error {
+	return h.err
+}
+
+// Is reports whether the wrapped error matches target.
+func (h *handlerError) Is(target error) bool {
+	if h.written && target == ErrResponseWritten {
+		return true
+	}
+
+	return errors.Is(h.err, target)
+}
+
+// As finds the first error in the wrapped error's tree that matches target.
+// It is only consulted after [errors.As] has checked the error itself, so a
+// target such as *[StatusMsg] or *[Code] finds this error, while a target
+// for a domain error finds the error it wraps.
+func (h *handlerError) As(target any) bool {
+	return errors.As(h.err, target)
+}
@@ -0,0 +1,91 @@
+package httperr
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"testing"
+)
+
+func TestStatusMsg(t *testing.T) {
+	tests := []struct {
+		status int
+		msg    string
+		want   string
+	}{
+		{status: http.StatusBadRequest, want: "Bad Request"},
+		{status: http.StatusNotFound, want: "Not Found"},
+		{status: http.StatusInternalServerError, want: "Internal Server Error"},
+		{status: http.StatusNotFound, msg: "no such user", want: "no such user"},
+		{status: http.StatusConflict, msg: "already exists", want: "already exists"},
+	}
+
+	for _, tt := range tests {
+		err := NewError(nil, tt.status, WithMessage(tt.msg)).(StatusMsg)
+		status, msg := err.StatusMsg()
+		if status != tt.status || msg != tt.want {
+			t.Errorf("NewError(nil, %d, WithMessage(%q)).StatusMsg() = (%d, %q), want (%d, %q)",
+				tt.status, tt.msg, status, msg, tt.status, tt.want)
+		}
+	}
+}
+
+type domainError struct{ id string }
+
+func (e *domainError) Error() string { return "no such thing " + e.id }
+
+func TestErrorsAsFindsWrapperAndCause(t *testing.T) {
+	err := fmt.Errorf("loading: %w", NewError(&domainError{id: "42"}, http.StatusNotFound))
+
+	var he *handlerError
+	if !errors.As(err, &he) || he.status != http.StatusNotFound {
+		t.Errorf("errors.As(*handlerError) = %v, want the wrapper", he)
+	}
+
+	var statusMsg StatusMsg
+	if !errors.As(err, &statusMsg) {
+		t.Error("errors.As(StatusMsg) = false, want the wrapper")
+	} else if status, _ := statusMsg.StatusMsg(); status != http.StatusNotFound {
+		t.Errorf("StatusMsg status = %d, want %d", status, http.StatusNotFound)
+	}
+
+	var domain *domainError
+	if !errors.As(err, &domain) || domain.id != "42" {
+		t.Errorf("errors.As(*domainError) = %v, want the cause", domain)
+	}
+}
+
+func TestNewErrorNilCause(t *testing.T) {
+	err := NewError(nil, http.StatusNotFound, WithResponseWritten())
+
+	if errors.Unwrap(err) != nil {
+		t.Errorf("Unwrap = %v, want nil", errors.Unwrap(err))
+	}
+
+	var statusMsg StatusMsg
+	if !errors.As(err, &statusMsg) {
+		t.Error("errors.As didn't find the StatusMsg")
+	}
+	if !errors.Is(err, ErrResponseWritten) {
+		t.Error("errors.Is(err, ErrResponseWritten) = false, want true")
+	}
+}
+
+func TestErrorLogsResolvedMessage(t *testing.T) {
+	err := NewError(errors.New("boom"), http.StatusNotFound)
+
+	if got, want := err.Error(), `status=404 msg="Not Found" err="boom"`; got != want {
+		t.Errorf("Error = %q, want %q", got, want)
+	}
+
+	var msg string
+	for _, attr := range err.(slog.LogValuer).LogValue().Group() {
+		if attr.Key == "msg" {
+			msg = attr.Value.String()
+		}
+	}
+	if msg != "Not Found" {
+		t.Errorf("LogValue msg = %q, want %q", msg, "Not Found")
+	}
+}
@@ -0,0 +1,78 @@
+package httperr
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+// ETag returns a [Middleware] that sets an ETag derived from a hash of the
+// body on successful GET and HEAD responses, and responds with a 304 without a
+// body when the request's If-None-Match matches it. An ETag set by the next
+// [Handler] is used as is. No ETag is generated for a HEAD response without a
+// body, since it can't match the one of the GET.
+//
+// The whole response is buffered in memory to compute the hash, so it
+// shouldn't be used for large or streamed responses. If the next [Handler]
+// returns an error, the buffered response is discarded and the error is
+// returned without ETag processing.
+func ETag() Middleware {
+	return Named("httperr.ETag", func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			if r.Method != http.MethodGet && r.Method != http.MethodHead {
+				return next.ServeHTTP(w, r)
+			}
+
+			bw := &bufferedWriter{header: w.Header()}
+			if err := next.ServeHTTP(bw, r); err != nil {
+				return err
+			}
+
+			status := bw.statusOrOK()
+			if status != http.StatusOK {
+				w.WriteHeader(status)
+				w.Write(bw.body.Bytes())
+				return nil
+			}
+
+			// A handler that writes no body for HEAD would get the tag of an
+			// empty body, different from that of the GET.
+			etag := w.Header().Get("ETag")
+			if etag == "" && (r.Method == http.MethodGet || bw.body.Len() > 0) {
+				sum := sha256.Sum256(bw.body.Bytes())
+				etag = `"` + hex.EncodeToString(sum[:16]) + `"`
+				w.Header().Set("ETag", etag)
+			}
+
+			if etag != "" && etagMatch(r.Header.Get("If-None-Match"), etag) {
+				h := w.Header()
+				h.Del("Content-Type")
+				h.Del("Content-Length")
+				w.WriteHeader(http.StatusNotModified)
+				return nil
+			}
+
+			w.WriteHeader(status)
+			w.Write(bw.body.Bytes())
+			return nil
+		})
+	})
+}
+
+// etagMatch reports whether etag matches the If-None-Match header, using the
+// weak comparison that the header calls for.
+func etagMatch(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,29 @@
+package httperr
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestETagHead(t *testing.T) {
+	h := HandleErr(nil, nil)(ETag()(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		if r.Method == http.MethodGet {
+			io.WriteString(w, "hello")
+		}
+		return nil
+	})))
+
+	get := httptest.NewRecorder()
+	h.ServeHTTP(get, httptest.NewRequest(http.MethodGet, "/", nil))
+	if get.Header().Get("ETag") == "" {
+		t.Fatal("GET has no ETag")
+	}
+
+	head := httptest.NewRecorder()
+	h.ServeHTTP(head, httptest.NewRequest(http.MethodHead, "/", nil))
+	if got := head.Header().Get("ETag"); got != "" {
+		t.Errorf("HEAD without a body has ETag %q, want none", got)
+	}
+}
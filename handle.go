@@ -0,0 +1,629 @@
+package httperr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"syscall"
+)
+
+// ErrFunc is a function type for writing an error to the client. It matches
+// the signature of [http.Error].
+type ErrFunc func(w http.ResponseWriter, err string, code int)
+
+// HandleErr returns a [ToStd] that responds to the client with errFunc and
+// writes the error to errWriter whenever a [Handler] returns an error. Errors
+// that don't satisfy [StatusMsg] are treated as a 500. A nil errWriter
+// defaults to [os.Stderr] and a nil errFunc defaults to [http.Error].
+// Headers the handler set are kept on the error response, except that the
+// Content-Type set by errFunc for its body replaces the handler's.
+//
+// Each error is logged with a single call to errWriter.Write, and the calls
+// made by the returned [ToStd] are serialized, so log lines from concurrent
+// requests don't interleave. A writer that is shared with anything else must
+// be safe for concurrent use itself, as [os.Stderr] is.
+func HandleErr(errWriter io.Writer, errFunc ErrFunc, opts ...HandleOption) ToStd {
+	if errWriter == nil {
+		errWriter = os.Stderr
+	}
+	errWriter = &lockedWriter{w: errWriter}
+
+	eh := newErrHandler(errFunc, opts)
+	eh.log = func(w http.ResponseWriter, r *http.Request, err error, status int, msg string) {
+		fmt.Fprintln(errWriter, err)
+	}
+	eh.logPanic = func(w http.ResponseWriter, r *http.Request, err error, v any) {
+		fmt.Fprintf(errWriter, "panic while handling error: %v: %v\n", v, err)
+	}
+
+	return eh.toStd
+}
+
+// lockedWriter serializes the calls to Write of w.
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (lw *lockedWriter) Write(b []byte) (int, error) {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+	return lw.w.Write(b)
+}
+
+// HandleErrWithRequest returns a [ToStd] like [HandleErr] that writes errors
+// to the client with the request-aware render. A nil render defaults to
+// [http.Error].
+func HandleErrWithRequest(errWriter io.Writer, render ErrRenderer, opts ...HandleOption) ToStd {
+	if render != nil {
+		opts = append([]HandleOption{WithErrRenderer(render)}, opts...)
+	}
+
+	return HandleErr(errWriter, nil, opts...)
+}
+
+// HandleErrSlog returns a [ToStd] like [HandleErr] that logs errors to logger
+// with structured attributes, at the level chosen by [WithSeverity]. A nil
+// logger defaults to [slog.Default].
+func HandleErrSlog(logger *slog.Logger, errFunc ErrFunc, opts ...HandleOption) ToStd {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	eh := newErrHandler(errFunc, opts)
+	eh.log = func(w http.ResponseWriter, r *http.Request, err error, status int, msg string) {
+		logError(logger, eh.severity(status, err), w, r, err, status, msg)
+	}
+	eh.logQuiet = func(w http.ResponseWriter, r *http.Request, err error, status int, msg string) {
+		logError(logger, slog.LevelDebug, w, r, err, status, msg)
+	}
+	eh.logPanic = func(w http.ResponseWriter, r *http.Request, err error, v any) {
+		logger.LogAttrs(r.Context(), slog.LevelError, "panic while handling error",
+			slog.Any("panic", v),
+			slog.Any("error", err),
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+		)
+	}
+
+	return eh.toStd
+}
+
+func logError(logger *slog.Logger, level slog.Level, w http.ResponseWriter, r *http.Request, err error, status int, msg string) {
+	attrs := []slog.Attr{
+		slog.Int("status", status),
+		slog.String("response_msg", msg),
+		slog.String("method", r.Method),
+		slog.String("path", r.URL.Path),
+		slog.Any("error", err),
+	}
+	if id := requestID(w, r); id != "" {
+		attrs = append(attrs, slog.String("request_id", id))
+	}
+
+	// An error that isn't a [slog.LogValuer] itself may still wrap one with
+	// fields.
+	var fielded interface{ Fields() []slog.Attr }
+	if _, ok := err.(slog.LogValuer); !ok && errors.As(err, &fielded) && len(fielded.Fields()) > 0 {
+		attrs = append(attrs, slog.Attr{Key: "fields", Value: slog.GroupValue(fielded.Fields()...)})
+	}
+
+	var stack interface{ StackTrace() []uintptr }
+	if errors.As(err, &stack) && len(stack.StackTrace()) > 0 {
+		attrs = append(attrs, slog.String("stack", formatStack(stack.StackTrace())))
+	}
+
+	logger.LogAttrs(r.Context(), level, "handler error", attrs...)
+}
+
+// StatusClientClosedRequest is the non-standard status code used to record
+// that the client closed the connection before the response was written.
+const StatusClientClosedRequest = 499
+
+// HandleOption configures the error handling of [HandleErr] and
+// [HandleErrSlog].
+type HandleOption func(*errHandler)
+
+// WithClientCancelStatus treats errors matching [context.Canceled] as the
+// client having gone away. Instead of a 500, only the status code is written
+// without a body, and the error isn't logged. [StatusClientClosedRequest] is a
+// common choice of code.
+func WithClientCancelStatus(code int) HandleOption {
+	return func(eh *errHandler) {
+		eh.cancelStatus = code
+	}
+}
+
+// ErrRenderer is a function type for writing an error to the client, like
+// [ErrFunc], that also has access to the request.
+type ErrRenderer func(w http.ResponseWriter, r *http.Request, status int, msg string)
+
+// WithErrRenderer writes errors to the client with render in place of the
+// [ErrFunc].
+func WithErrRenderer(render ErrRenderer) HandleOption {
+	return func(eh *errHandler) {
+		eh.render = render
+	}
+}
+
+// ReportFunc is a function type for forwarding errors to an error tracking
+// service.
+type ReportFunc func(r *http.Request, err error)
+
+// WithReport calls report for every error whose status satisfies match, after
+// the response has been written. A nil match reports statuses of 500 and
+// above. The report is made on the request goroutine, so report should not
+// block, and a panic in report is recovered and ignored.
+func WithReport(report ReportFunc, match func(status int) bool) HandleOption {
+	if match == nil {
+		match = func(status int) bool {
+			return status >= http.StatusInternalServerError
+		}
+	}
+
+	return func(eh *errHandler) {
+		eh.report = func(r *http.Request, err error, status int) {
+			if !match(status) {
+				return
+			}
+
+			defer func() { recover() }()
+			report(r, err)
+		}
+	}
+}
+
+// Mapper translates an error that carries no status of its own, such as a
+// domain error, into a status and client message. It reports whether it
+// handled the error.
+type Mapper func(err error) (status int, msg string, handled bool)
+
+// WithMapper uses mapper for errors that don't satisfy [StatusMsg]. Errors
+// that mapper doesn't handle are treated as a 500.
+func WithMapper(mapper Mapper) HandleOption {
+	return func(eh *errHandler) {
+		eh.mapper = mapper
+	}
+}
+
+// WithSeverity chooses the level at which [HandleErrSlog] logs each error. By
+// default statuses of 500 and above are logged at [slog.LevelError], 4xx
+// statuses at [slog.LevelWarn], and the rest at [slog.LevelInfo].
+func WithSeverity(severity func(status int, err error) slog.Level) HandleOption {
+	return func(eh *errHandler) {
+		eh.severity = severity
+	}
+}
+
+func defaultSeverity(status int, err error) slog.Level {
+	switch {
+	case status >= http.StatusInternalServerError:
+		return slog.LevelError
+	case status >= http.StatusBadRequest:
+		return slog.LevelWarn
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// IsConnClosed reports whether err is the result of the client closing the
+// connection, such as a broken pipe or connection reset while writing the
+// response. [HandleErr] doesn't respond to or log these errors, and
+// [HandleErrSlog] logs them at [slog.LevelDebug].
+func IsConnClosed(err error) bool {
+	return errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, net.ErrClosed)
+}
+
+// WithCharset sets the charset parameter of the Content-Type of error
+// responses written by the [ErrFunc] or [ErrRenderer], replacing the utf-8
+// used by the built-in renderers and [http.Error].
+func WithCharset(charset string) HandleOption {
+	return func(eh *errHandler) {
+		eh.charset = charset
+	}
+}
+
+// WithNoSniff sets X-Content-Type-Options: nosniff on every error response
+// written by the [ErrFunc] or [ErrRenderer], including custom ones that don't
+// set it themselves.
+func WithNoSniff() HandleOption {
+	return func(eh *errHandler) {
+		eh.noSniff = true
+	}
+}
+
+// WithRequestIDInBody includes the request ID, when there is one, in the body
+// of error responses written by [JSONErrRenderer], [HTMLErrFunc] and
+// [NegotiateErrFunc], so clients can quote it when reporting a problem. The
+// request ID is always echoed in the [RequestIDHeader] of error responses.
+func WithRequestIDInBody() HandleOption {
+	return func(eh *errHandler) {
+		eh.requestIDInBody = true
+	}
+}
+
+// WithErrorTrailer sends the client message of an error returned after the
+// response has started, when its status can no longer be sent, in the X-Error
+// response trailer. Trailers are only sent over HTTP/2 or a chunked HTTP/1.1
+// response, which a response is once it has been flushed or outgrown the
+// server's buffer, and only seen by clients that read them.
+func WithErrorTrailer() HandleOption {
+	return func(eh *errHandler) {
+		eh.errorTrailer = true
+	}
+}
+
+// WithProductionMode, when enabled, replaces the client message of every
+// error with a status of 500 or above with the status text, such as "Internal
+// Server Error", so that no internal detail reaches clients. Bodies attached
+// with [WithResponseBody] or [WithBodyReader] aren't sent for them either.
+// The error is still logged with its real message, and 4xx messages, which
+// are meant for clients, are sent as usual.
+func WithProductionMode(enabled bool) HandleOption {
+	return func(eh *errHandler) {
+		eh.production = enabled
+	}
+}
+
+type errHandler struct {
+	render          ErrRenderer
+	log             func(w http.ResponseWriter, r *http.Request, err error, status int, msg string)
+	logQuiet        func(w http.ResponseWriter, r *http.Request, err error, status int, msg string)
+	logPanic        func(w http.ResponseWriter, r *http.Request, err error, v any)
+	cancelStatus    int
+	report          func(r *http.Request, err error, status int)
+	translator      Translator
+	mapper          Mapper
+	severity        func(status int, err error) slog.Level
+	charset         string
+	noSniff         bool
+	requestIDInBody bool
+	errorTrailer    bool
+	details         func(err error) ErrorDetails
+	renderDetails   DetailsRenderer
+	production      bool
+}
+
+func newErrHandler(errFunc ErrFunc, opts []HandleOption) *errHandler {
+	if errFunc == nil {
+		errFunc = http.Error
+	}
+
+	eh := &errHandler{
+		render: func(w http.ResponseWriter, r *http.Request, status int, msg string) {
+			errFunc(w, msg, status)
+		},
+		logQuiet: func(http.ResponseWriter, *http.Request, error, int, string) {},
+		report:   func(*http.Request, error, int) {},
+		severity: defaultSeverity,
+	}
+
+	for _, opt := range opts {
+		opt(eh)
+	}
+
+	return eh
+}
+
+func (eh *errHandler) toStd(h Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rw, pooled := acquireResponseWriter(w)
+		if pooled {
+			defer releaseResponseWriter(rw)
+		}
+
+		// Requests without an error return here, before any of the error
+		// handling, without allocating.
+		err := h.ServeHTTP(rw, r)
+		if err == nil {
+			return
+		}
+
+		storeError(r.Context(), err)
+		defer eh.recoverHandling(rw, r, err)
+
+		if eh.cancelStatus != 0 && errors.Is(err, context.Canceled) {
+			if !rw.Written() {
+				rw.WriteHeader(eh.cancelStatus)
+			}
+			return
+		}
+
+		// The client is gone, so there's nothing to respond to and nothing
+		// wrong with the server.
+		if IsConnClosed(err) {
+			status, msg, _ := eh.resolve(r, err)
+			eh.logQuiet(w, r, err, status, msg)
+			return
+		}
+
+		// Once the response has started the status can't be changed, so the
+		// error is only logged, unless the handler wrote the response on
+		// purpose.
+		if rw.Written() || errors.Is(err, ErrResponseWritten) {
+			status, msg, _ := eh.resolve(r, err)
+			logErr := err
+			if !errors.Is(err, ErrResponseWritten) {
+				logErr = eh.respondStarted(rw, err, status, msg)
+			}
+			eh.log(w, r, logErr, status, msg)
+			eh.report(r, err, status)
+			return
+		}
+
+		status, msg, details := eh.resolve(r, err)
+		if id := requestID(w, r); id != "" {
+			rw.Header().Set(RequestIDHeader, id)
+		}
+		setHeaders(rw, err)
+		logErr := err
+		if bodyErr := eh.respond(rw, r, err, details, status, msg); bodyErr != nil {
+			logErr = fmt.Errorf("error body not fully sent: %v: %w", bodyErr, err)
+		}
+		eh.log(w, r, logErr, status, msg)
+		eh.report(r, err, status)
+	})
+}
+
+// respondStarted reports err on a response that has already started: an event
+// stream is sent a final error event, and with [WithErrorTrailer] the message
+// is sent in the X-Error trailer. It returns the error to log, which stands
+// out when status couldn't be sent.
+func (eh *errHandler) respondStarted(rw *ResponseWriter, err error, status int, msg string) error {
+	if isEventStream(rw.Header()) {
+		writeSSEError(rw, msg)
+	}
+
+	if eh.errorTrailer {
+		rw.Header().Set(http.TrailerPrefix+"X-Error", msg)
+	}
+
+	if rw.Status() != status {
+		return fmt.Errorf("status %d not sent, response already started with %d: %w", status, rw.Status(), err)
+	}
+
+	return err
+}
+
+// recoverHandling recovers from a panic while handling err, such as in a
+// custom [ErrFunc], so that it can't take down the server. The panic is
+// logged separately from err, and a minimal 500 is written if the response
+// hasn't started.
+func (eh *errHandler) recoverHandling(rw *ResponseWriter, r *http.Request, err error) {
+	v := recover()
+	if v == nil {
+		return
+	}
+
+	if v == http.ErrAbortHandler {
+		panic(v)
+	}
+
+	if !rw.Written() {
+		status := http.StatusInternalServerError
+		http.Error(rw, http.StatusText(status), status)
+	}
+
+	func() {
+		defer func() { recover() }()
+		eh.logPanic(rw, r, err, v)
+	}()
+}
+
+// resolve determines the status and message for err, and returns the
+// [ErrorDetails] they were taken from when [WithErrorDetails] is used.
+func (eh *errHandler) resolve(r *http.Request, err error) (int, string, *ErrorDetails) {
+	status := http.StatusInternalServerError
+	msg := http.StatusText(status)
+	var details *ErrorDetails
+	var statusMsg StatusMsg
+	if eh.details != nil {
+		d := eh.details(err)
+		details = &d
+		if details.Status != 0 {
+			status = details.Status
+		}
+		msg = details.Message
+	} else if errors.As(err, &statusMsg) {
+		status, msg = statusMsg.StatusMsg()
+	} else if eh.mapper != nil {
+		if s, m, ok := eh.mapper(err); ok {
+			status, msg = s, m
+		}
+	}
+
+	if translated, ok := eh.translate(r, err); ok {
+		msg = translated
+	}
+
+	if eh.production && status >= http.StatusInternalServerError {
+		msg = http.StatusText(status)
+	}
+
+	// An empty body is confusing for clients, whichever renderer is used.
+	if msg == "" {
+		msg = http.StatusText(status)
+	}
+
+	return status, msg, details
+}
+
+// setHeaders sets any headers carried by err on w. Headers must be set before
+// the status code is written.
+func setHeaders(w http.ResponseWriter, err error) {
+	var headers interface{ Headers() http.Header }
+	if errors.As(err, &headers) {
+		for key, values := range headers.Headers() {
+			w.Header()[key] = append([]string(nil), values...)
+		}
+	}
+}
+
+// respond writes the response for err. It returns the error from streaming a
+// body attached with [WithBodyReader], which is too late to change the
+// response for.
+func (eh *errHandler) respond(w http.ResponseWriter, r *http.Request, err error, details *ErrorDetails, status int, msg string) error {
+	// Responses to HEAD requests must not include a body.
+	if r.Method == http.MethodHead {
+		w = headWriter{w}
+	}
+
+	if details != nil {
+		for key, values := range details.Header {
+			w.Header()[key] = append([]string(nil), values...)
+		}
+	}
+
+	// A raw body of a 5xx could carry as much detail as its message.
+	raw := !eh.production || status < http.StatusInternalServerError
+
+	var body interface {
+		ResponseBody() (string, []byte, bool)
+	}
+	if raw && errors.As(err, &body) {
+		if contentType, b, ok := body.ResponseBody(); ok {
+			writeBody(w, status, contentType, b)
+			return nil
+		}
+	}
+
+	var bodyReader interface {
+		ResponseBodyReader() (string, io.Reader, bool)
+	}
+	if raw && errors.As(err, &bodyReader) {
+		if contentType, br, ok := bodyReader.ResponseBodyReader(); ok {
+			return writeBodyReader(w, status, contentType, br)
+		}
+	}
+
+	// The body is in the renderer's format, so its Content-Type replaces one
+	// set by the handler, or carried by the error, which is only kept when
+	// the renderer sets none.
+	contentType := w.Header().Get("Content-Type")
+	w.Header().Del("Content-Type")
+	if contentType != "" || eh.charset != "" || eh.noSniff {
+		w = &renderWriter{ResponseWriter: w, contentType: contentType, charset: eh.charset, noSniff: eh.noSniff}
+	}
+
+	if details != nil && eh.renderDetails != nil {
+		d := *details
+		d.Status, d.Message = status, msg
+		eh.renderDetails(w, r, d)
+		return nil
+	}
+
+	// The request carries what the built-in renderers include in the body
+	// beyond the status and message.
+	var codeValue string
+	var code Code
+	if details != nil {
+		codeValue = details.Code
+	} else if errors.As(err, &code) {
+		codeValue = code.Code()
+	}
+	if codeValue != "" {
+		r = r.WithContext(context.WithValue(r.Context(), errorCodeKey{}, codeValue))
+	}
+	if eh.requestIDInBody {
+		r = r.WithContext(context.WithValue(r.Context(), requestIDInBodyKey{}, true))
+	}
+
+	eh.render(w, r, status, msg)
+	return nil
+}
+
+// headWriter discards the body of a response, keeping the headers and status.
+type headWriter struct {
+	http.ResponseWriter
+}
+
+func (w headWriter) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+// renderWriter adjusts the headers set by an [ErrFunc] or [ErrRenderer] just
+// before they are written. contentType is the Content-Type from before the
+// renderer ran, restored if the renderer sets none.
+type renderWriter struct {
+	http.ResponseWriter
+	contentType string
+	charset     string
+	noSniff     bool
+	wroteHeader bool
+}
+
+func (w *renderWriter) WriteHeader(code int) {
+	w.prepare()
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *renderWriter) Write(b []byte) (int, error) {
+	w.prepare()
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap returns the underlying [http.ResponseWriter] for use with
+// [http.ResponseController].
+func (w *renderWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *renderWriter) prepare() {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+
+	h := w.Header()
+	if w.noSniff {
+		h.Set("X-Content-Type-Options", "nosniff")
+	}
+
+	if w.contentType != "" && h.Get("Content-Type") == "" {
+		h.Set("Content-Type", w.contentType)
+		return
+	}
+
+	if w.charset == "" {
+		return
+	}
+
+	mediaType, params, err := mime.ParseMediaType(h.Get("Content-Type"))
+	if err != nil {
+		return
+	}
+	params["charset"] = w.charset
+	h.Set("Content-Type", mime.FormatMediaType(mediaType, params))
+}
+
+func writeBodyReader(w http.ResponseWriter, status int, contentType string, body io.Reader) error {
+	if closer, ok := body.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	if contentType != "" {
+		w.Header().Set("Content-Type", contentType)
+	}
+	w.Header().Del("Content-Length")
+	w.WriteHeader(status)
+	_, err := io.Copy(w, body)
+	return err
+}
+
+func writeBody(w http.ResponseWriter, status int, contentType string, body []byte) {
+	if contentType != "" {
+		w.Header().Set("Content-Type", contentType)
+	}
+	w.Header().Del("Content-Length")
+	w.WriteHeader(status)
+	w.Write(body)
+}
@@ -0,0 +1,186 @@
+package httperr
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandleErrLogsOneLinePerError(t *testing.T) {
+	var buf bytes.Buffer
+	h := HandleErr(&buf, nil)(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return NewError(errors.New("boom"), http.StatusBadRequest)
+	}))
+
+	for range 2 {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+
+	lines := bytes.Split(bytes.TrimSuffix(buf.Bytes(), []byte("\n")), []byte("\n"))
+	if !bytes.HasSuffix(buf.Bytes(), []byte("\n")) || len(lines) != 2 {
+		t.Errorf("log = %q, want two newline terminated lines", buf.String())
+	}
+}
+
+// emptyMsgError satisfies [StatusMsg] with an empty message.
+type emptyMsgError struct{}
+
+func (emptyMsgError) Error() string            { return "empty" }
+func (emptyMsgError) StatusMsg() (int, string) { return http.StatusNotFound, "" }
+
+func TestHandleErrEmptyMessage(t *testing.T) {
+	var gotMsg string
+	errFunc := func(w http.ResponseWriter, msg string, code int) {
+		gotMsg = msg
+		http.Error(w, msg, code)
+	}
+
+	h := HandleErr(io.Discard, errFunc)(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return emptyMsgError{}
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if want := http.StatusText(http.StatusNotFound); gotMsg != want {
+		t.Errorf("msg = %q, want %q", gotMsg, want)
+	}
+}
+
+func TestHandleErrHeadHasNoBody(t *testing.T) {
+	h := HandleErr(io.Discard, nil)(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return NewError(nil, http.StatusNotFound)
+	}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodHead, "/", nil))
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+	if rec.Body.Len() != 0 {
+		t.Errorf("body = %q, want none", rec.Body.String())
+	}
+}
+
+func TestHandleErrAfterFlush(t *testing.T) {
+	var called bool
+	errFunc := func(w http.ResponseWriter, msg string, code int) {
+		called = true
+		http.Error(w, msg, code)
+	}
+
+	h := HandleErr(io.Discard, errFunc)(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		io.WriteString(w, "partial")
+		http.NewResponseController(w).Flush()
+		w.WriteHeader(http.StatusInternalServerError)
+		return errors.New("boom")
+	}))
+
+	var serverLog bytes.Buffer
+	srv := httptest.NewUnstartedServer(h)
+	srv.Config.ErrorLog = log.New(&serverLog, "", 0)
+	srv.Start()
+	defer srv.Close()
+
+	res, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+	srv.Close()
+
+	if called {
+		t.Error("errFunc was called after the response started")
+	}
+	if res.StatusCode != http.StatusOK || string(body) != "partial" {
+		t.Errorf("response = %d %q, want %d %q", res.StatusCode, body, http.StatusOK, "partial")
+	}
+	if strings.Contains(serverLog.String(), "superfluous") {
+		t.Errorf("server logged %q", serverLog.String())
+	}
+}
+
+func TestHandleErrKeepsHandlerHeaders(t *testing.T) {
+	h := HandleErr(io.Discard, nil)(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Cache-Control", "private")
+		w.Header().Set("Content-Type", "text/html")
+		return NewError(nil, http.StatusBadRequest, WithMessage("<script>alert(1)</script>"))
+	}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if got := rec.Header().Get("Cache-Control"); got != "private" {
+		t.Errorf("Cache-Control = %q, want %q", got, "private")
+	}
+	if got, want := rec.Header().Get("Content-Type"), "text/plain; charset=utf-8"; got != want {
+		t.Errorf("Content-Type = %q, want %q", got, want)
+	}
+}
+
+func TestHandleErrKeepsHandlerContentTypeWhenRendererSetsNone(t *testing.T) {
+	errFunc := func(w http.ResponseWriter, msg string, code int) {
+		w.WriteHeader(code)
+		io.WriteString(w, msg)
+	}
+	h := HandleErr(io.Discard, errFunc)(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-Type", "application/vnd.example")
+		return errors.New("boom")
+	}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if got, want := rec.Header().Get("Content-Type"), "application/vnd.example"; got != want {
+		t.Errorf("Content-Type = %q, want %q", got, want)
+	}
+}
+
+func TestHandleErrNilErrorAllocs(t *testing.T) {
+	h := HandleErr(io.Discard, nil)(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return nil
+	}))
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	// Warm up the pool of response writer wrappers.
+	h.ServeHTTP(w, r)
+
+	// A garbage collection can empty the pool during a run, so the average
+	// may be a fraction above zero, but any allocation per request is at
+	// least one.
+	if allocs := testing.AllocsPerRun(100, func() { h.ServeHTTP(w, r) }); allocs >= 1 {
+		t.Errorf("allocs = %v, want 0", allocs)
+	}
+}
+
+func BenchmarkHandleErrNil(b *testing.B) {
+	h := HandleErr(io.Discard, nil)(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return nil
+	}))
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	b.ReportAllocs()
+	for range b.N {
+		h.ServeHTTP(w, r)
+	}
+}
+
+func BenchmarkHandleErrError(b *testing.B) {
+	err := NewError(errors.New("boom"), http.StatusBadRequest)
+	h := HandleErr(io.Discard, nil)(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return err
+	}))
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	b.ReportAllocs()
+	for range b.N {
+		h.ServeHTTP(httptest.NewRecorder(), r)
+	}
+}
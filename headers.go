@@ -0,0 +1,86 @@
+package httperr
+
+import (
+	"mime"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// RequireHeaders returns a [Middleware] that returns a 400 error naming the
+// first of names that is missing from the request or has an empty value.
+func RequireHeaders(names ...string) Middleware {
+	return Named("httperr.RequireHeaders", func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			for _, name := range names {
+				if r.Header.Get(name) == "" {
+					return NewError(nil, http.StatusBadRequest, WithMessage("missing header "+http.CanonicalHeaderKey(name)))
+				}
+			}
+
+			return next.ServeHTTP(w, r)
+		})
+	})
+}
+
+// RequireHeaderValue returns a [Middleware] that returns a 400 error unless
+// the request header name has the value expected, such as for an API version
+// header.
+func RequireHeaderValue(name, expected string) Middleware {
+	name = http.CanonicalHeaderKey(name)
+
+	return Named("httperr.RequireHeaderValue", func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			value := r.Header.Get(name)
+			if value == "" {
+				return NewError(nil, http.StatusBadRequest, WithMessage("missing header "+name))
+			}
+
+			if value != expected {
+				return NewError(nil, http.StatusBadRequest, WithMessage("invalid header "+name))
+			}
+
+			return next.ServeHTTP(w, r)
+		})
+	})
+}
+
+// RequireContentType returns a [Middleware] that returns a 415 error for
+// requests whose Content-Type, ignoring parameters such as charset, isn't one
+// of types, such as "application/json". GET, HEAD and DELETE requests, which
+// don't usually have a body, aren't checked. Use [RequireContentTypeStrict] to
+// check every request.
+func RequireContentType(types ...string) Middleware {
+	return Named("httperr.RequireContentType", requireContentType(false, types))
+}
+
+// RequireContentTypeStrict is like [RequireContentType], but checks requests
+// of every method.
+func RequireContentTypeStrict(types ...string) Middleware {
+	return Named("httperr.RequireContentTypeStrict", requireContentType(true, types))
+}
+
+func requireContentType(strict bool, types []string) Middleware {
+	allowed := make([]string, len(types))
+	for i, t := range types {
+		allowed[i] = strings.ToLower(t)
+	}
+
+	return func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodDelete:
+				if !strict {
+					return next.ServeHTTP(w, r)
+				}
+			}
+
+			mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+			if err != nil || !slices.Contains(allowed, mediaType) {
+				return NewError(nil, http.StatusUnsupportedMediaType, WithMessage("unsupported content type"))
+			}
+
+			return next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package httperr
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// healthTimeout bounds how long the checks of [Health] may run.
+const healthTimeout = 5 * time.Second
+
+// Health returns a [HandlerFunc] for health and readiness endpoints. It runs
+// checks in order with a context that is canceled when the request is, or
+// after 5 seconds, and stops once the context is done. It responds with a 200
+// when they all pass, or returns a 503 error naming the index of the first
+// failed check. The error of the check is wrapped for the logs, but never sent
+// to the client, since it can hold details such as addresses.
+func Health(checks ...func(context.Context) error) HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		ctx, cancel := context.WithTimeout(r.Context(), healthTimeout)
+		defer cancel()
+
+		for i, check := range checks {
+			err := ctx.Err()
+			if err == nil {
+				err = check(ctx)
+			}
+
+			if err != nil {
+				return NewError(
+					fmt.Errorf("health check %d: %w", i, err),
+					http.StatusServiceUnavailable,
+					WithMessage(fmt.Sprintf("health check %d failed", i)),
+				)
+			}
+		}
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		io.WriteString(w, "ok\n")
+		return nil
+	}
+}
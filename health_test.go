@@ -0,0 +1,47 @@
+package httperr
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHealthHidesCheckErrors(t *testing.T) {
+	h := HandleErr(io.Discard, nil)(Health(
+		func(context.Context) error { return nil },
+		func(context.Context) error { return errors.New("dial tcp 10.0.0.5:5432: refused") },
+	))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+	if body := rec.Body.String(); strings.Contains(body, "10.0.0.5") || !strings.Contains(body, "health check 1 failed") {
+		t.Errorf("body = %q, want the failed check without its error", body)
+	}
+}
+
+func TestHealthStopsWhenCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var ran bool
+	h := Health(
+		func(context.Context) error { cancel(); return nil },
+		func(context.Context) error { ran = true; return nil },
+	)
+
+	r := httptest.NewRequest(http.MethodGet, "/healthz", nil).WithContext(ctx)
+	err := h.ServeHTTP(httptest.NewRecorder(), r)
+
+	if ran {
+		t.Error("check ran after the request was canceled")
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,72 @@
+package httperr
+
+import (
+	"bytes"
+	"html/template"
+	"net/http"
+)
+
+// HTMLErrData is the data passed to the template of [HTMLErrFuncWithTemplate].
+// RequestID is only set when [WithRequestIDInBody] is used.
+type HTMLErrData struct {
+	Status    int
+	Message   string
+	RequestID string
+}
+
+var defaultHTMLTemplate = template.Must(template.New("error").Funcs(template.FuncMap{
+	"statusText": http.StatusText,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Status}} {{statusText .Status}}</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 4rem auto; max-width: 40rem; padding: 0 1rem; color: #222; }
+h1 { font-size: 1.5rem; }
+small { color: #666; }
+</style>
+</head>
+<body>
+<h1>{{.Status}} {{statusText .Status}}</h1>
+<p>{{.Message}}</p>
+{{with .RequestID}}<p><small>Request ID: {{.}}</small></p>{{end}}
+</body>
+</html>
+`))
+
+// HTMLErrFunc is an [ErrRenderer] that writes the error as a minimal HTML page.
+func HTMLErrFunc(w http.ResponseWriter, r *http.Request, status int, msg string) {
+	renderHTML(w, r, defaultHTMLTemplate, status, msg)
+}
+
+// HTMLErrFuncWithTemplate returns an [ErrRenderer] that writes the error by
+// executing t with [HTMLErrData]. The output is buffered, and if t fails to
+// execute the error is written with [http.Error] instead.
+func HTMLErrFuncWithTemplate(t *template.Template) ErrRenderer {
+	return func(w http.ResponseWriter, r *http.Request, status int, msg string) {
+		renderHTML(w, r, t, status, msg)
+	}
+}
+
+func renderHTML(w http.ResponseWriter, r *http.Request, t *template.Template, status int, msg string) {
+	var buf bytes.Buffer
+	err := t.Execute(&buf, HTMLErrData{
+		Status:    status,
+		Message:   msg,
+		RequestID: bodyRequestID(w, r),
+	})
+	if err != nil {
+		http.Error(w, msg, status)
+		return
+	}
+
+	setContentType(w.Header(), "text/html")
+	w.WriteHeader(status)
+	if !bodyAllowed(status) {
+		return
+	}
+
+	w.Write(buf.Bytes())
+}
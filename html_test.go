@@ -0,0 +1,35 @@
+package httperr
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHTMLErrFuncRequestIDOptIn(t *testing.T) {
+	tests := []struct {
+		opts []HandleOption
+		want bool
+	}{
+		{want: false},
+		{opts: []HandleOption{WithRequestIDInBody()}, want: true},
+	}
+
+	for _, tt := range tests {
+		h := HandleErrWithRequest(io.Discard, HTMLErrFunc, tt.opts...)(Wrap(func(w http.ResponseWriter, r *http.Request) error {
+			return NewError(nil, http.StatusNotFound)
+		}, RequestIDWithGenerator(func() string { return "req-123" })))
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		if got := strings.Contains(rec.Body.String(), "req-123"); got != tt.want {
+			t.Errorf("body contains request ID = %v, want %v", got, tt.want)
+		}
+		if got := rec.Header().Get(RequestIDHeader); got != "req-123" {
+			t.Errorf("%s = %q, want %q", RequestIDHeader, got, "req-123")
+		}
+	}
+}
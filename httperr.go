@@ -2,6 +2,7 @@ package httperr
 
 import (
 	"net/http"
+	"sync/atomic"
 )
 
 // Handler responds to an http request and can return an error.
@@ -24,33 +25,87 @@ type Middleware func(Handler) Handler
 // [Middleware] and a [HandlerFunc]. The first [Middlware] provided is the first
 // invoked on a request.
 func WrapCommon(common ...Middleware) func(HandlerFunc, ...Middleware) Handler {
+	wrap := WrapCommonHandler(common...)
 	return func(h HandlerFunc, specific ...Middleware) Handler {
-		handler := Wrap(h, specific...)
-		handler = Wrap(handler.ServeHTTP, common...)
+		return wrap(h, specific...)
+	}
+}
 
-		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
-			return handler.ServeHTTP(w, r)
-		})
+// WrapCommonHandler is like [WrapCommon] for any [Handler], such as a struct
+// type implementing it.
+func WrapCommonHandler(common ...Middleware) func(Handler, ...Middleware) Handler {
+	return func(h Handler, specific ...Middleware) Handler {
+		handler := WrapHandler(h, specific...)
+		return WrapHandler(handler, common...)
 	}
 }
 
 // Wrap will wrap a set of [Middleware] around a [HandlerFunc]. The first
 // [Middleware] provided is the first invoked on a request.
 func Wrap(h HandlerFunc, mw ...Middleware) Handler {
-	if len(mw) == 0 {
-		return h
+	return WrapHandler(h, mw...)
+}
+
+// WrapHandler is like [Wrap] for any [Handler], such as a struct type
+// implementing it.
+func WrapHandler(h Handler, mw ...Middleware) Handler {
+	for i := len(mw) - 1; i >= 0; i-- {
+		h = mw[i](h)
 	}
 
-	return mw[0](Wrap(h, mw[1:]...))
+	return h
+}
+
+// WrapReverse will wrap a set of [Middleware] around a [HandlerFunc] in reverse
+// order: the last [Middleware] provided is the first invoked on a request.
+// WrapReverse(h, a, b) is the same as Wrap(h, b, a).
+func WrapReverse(h HandlerFunc, mw ...Middleware) Handler {
+	var handler Handler = h
+	for _, m := range mw {
+		handler = m(handler)
+	}
+
+	return handler
 }
 
 // ToStd is a function type for converting a [Handler] to an [http.Handler].
 type ToStd func(Handler) http.Handler
 
+var defaultToStd atomic.Pointer[ToStd]
+
+// SetDefaultToStd sets the [ToStd] used where a nil [ToStd] is passed, such as
+// to [WrapToStd], [WrapCommonToStd], [Handle], and [NewGroup]. It defaults to
+// HandleErr(nil, nil). The default is read when the handler is built, not per
+// request, so set it once at startup before registering handlers. A nil toStd
+// restores the default.
+func SetDefaultToStd(toStd ToStd) {
+	if toStd == nil {
+		defaultToStd.Store(nil)
+		return
+	}
+
+	defaultToStd.Store(&toStd)
+}
+
+// orDefault returns toStd, or the default set by [SetDefaultToStd] if it is
+// nil.
+func (toStd ToStd) orDefault() ToStd {
+	if toStd != nil {
+		return toStd
+	}
+
+	if d := defaultToStd.Load(); d != nil {
+		return *d
+	}
+
+	return HandleErr(nil, nil)
+}
+
 // WrapCommonToStd wraps a common set of [Middleware] around a specific set of
 // [Middleware] and a [HandlerFunc] in a way that is compatible with the stdlib
-// [http.Handler].
+// [http.Handler]. A nil toStd uses the default set by [SetDefaultToStd].
 func WrapCommonToStd(toStd ToStd, common ...Middleware) func(HandlerFunc, ...Middleware) http.Handler {
+	toStd = toStd.orDefault()
 	wrap := WrapCommon(common...)
 	return func(h HandlerFunc, specific ...Middleware) http.Handler {
 		return toStd(wrap(h, specific...))
@@ -58,8 +113,63 @@ func WrapCommonToStd(toStd ToStd, common ...Middleware) func(HandlerFunc, ...Mid
 }
 
 // WrapToStd wraps a set of [Middlware] around a [HandlerFunc] in a way that is
-// compatible with the stdlib [http.Handler].
+// compatible with the stdlib [http.Handler]. A nil toStd uses the default set
+// by [SetDefaultToStd].
 func WrapToStd(h HandlerFunc, toStd ToStd, mw ...Middleware) http.Handler {
 	handler := Wrap(h, mw...)
-	return toStd(handler)
+	return toStd.orDefault()(handler)
+}
+
+// Chain is an ordered, reusable set of [Middleware]. The first [Middleware] in
+// the chain is the first invoked on a request, the same as [Wrap].
+type Chain []Middleware
+
+// Then wraps the [Chain] around h.
+func (c Chain) Then(h HandlerFunc) Handler {
+	return Wrap(h, c...)
+}
+
+// Append returns a new [Chain] with mw added to the end. The receiver is not
+// modified.
+func (c Chain) Append(mw ...Middleware) Chain {
+	return append(c[:len(c):len(c)], mw...)
+}
+
+// Extend returns a new [Chain] with other added to the end. The receiver is
+// not modified.
+func (c Chain) Extend(other Chain) Chain {
+	return c.Append(other...)
+}
+
+// ToHandlerFunc converts a [Handler] to an [http.HandlerFunc] with toStd. A
+// nil toStd uses the default set by [SetDefaultToStd].
+func ToHandlerFunc(h Handler, toStd ToStd) http.HandlerFunc {
+	return toStd.orDefault()(h).ServeHTTP
+}
+
+// FromStd converts an [http.Handler] to a [HandlerFunc] that always returns
+// nil, so that it can run behind error-aware [Middleware]. It is the inverse
+// of [ToStd].
+func FromStd(h http.Handler) HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		h.ServeHTTP(w, r)
+		return nil
+	}
+}
+
+// FromStdWithStatus is like [FromStd], but returns an error when h responds
+// with a status of 400 or above, so that the error pipeline sees it. The
+// response has already been written by h, so the error matches
+// [ErrResponseWritten] and [HandleErr] only logs it.
+func FromStdWithStatus(h http.Handler) HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		rw := NewResponseWriter(w)
+		h.ServeHTTP(rw, r)
+
+		if status := rw.Status(); status >= http.StatusBadRequest {
+			return NewError(nil, status, WithResponseWritten())
+		}
+
+		return nil
+	}
 }
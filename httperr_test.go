@@ -0,0 +1,112 @@
+package httperr
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestWrapToStdUsesToStd(t *testing.T) {
+	var gotMsg string
+	var gotCode int
+	errFunc := func(w http.ResponseWriter, msg string, code int) {
+		gotMsg, gotCode = msg, code
+		w.WriteHeader(code)
+	}
+
+	h := WrapToStd(func(w http.ResponseWriter, r *http.Request) error {
+		return NewError(errors.New("boom"), http.StatusTeapot, WithMessage("short and stout"))
+	}, HandleErr(io.Discard, errFunc))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if gotCode != http.StatusTeapot || gotMsg != "short and stout" {
+		t.Errorf("errFunc got (%q, %d), want (%q, %d)", gotMsg, gotCode, "short and stout", http.StatusTeapot)
+	}
+	if rec.Code != http.StatusTeapot {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusTeapot)
+	}
+}
+
+// record returns a [Middleware] that appends name to calls when invoked.
+func record(calls *[]string, name string) Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			*calls = append(*calls, name)
+			return next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func serve(t *testing.T, h Handler) {
+	t.Helper()
+	if err := h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil)); err != nil {
+		t.Fatalf("ServeHTTP() = %v", err)
+	}
+}
+
+func TestWrapOrder(t *testing.T) {
+	var calls []string
+	h := Wrap(func(w http.ResponseWriter, r *http.Request) error {
+		calls = append(calls, "handler")
+		return nil
+	}, record(&calls, "a"), record(&calls, "b"), record(&calls, "c"))
+
+	serve(t, h)
+
+	if want := []string{"a", "b", "c", "handler"}; !slices.Equal(calls, want) {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+}
+
+// wrapRecursive is the recursive implementation that [Wrap] replaced, kept
+// for comparison.
+func wrapRecursive(h HandlerFunc, mw ...Middleware) Handler {
+	if len(mw) == 0 {
+		return h
+	}
+
+	return mw[0](wrapRecursive(h, mw[1:]...))
+}
+
+func benchmarkWrap(b *testing.B, wrap func(HandlerFunc, ...Middleware) Handler) {
+	mw := make([]Middleware, 1000)
+	for i := range mw {
+		mw[i] = func(next Handler) Handler { return next }
+	}
+	h := HandlerFunc(func(http.ResponseWriter, *http.Request) error { return nil })
+
+	b.ReportAllocs()
+	for range b.N {
+		wrap(h, mw...)
+	}
+}
+
+func BenchmarkWrap(b *testing.B) {
+	b.Run("iterative", func(b *testing.B) { benchmarkWrap(b, Wrap) })
+	b.Run("recursive", func(b *testing.B) { benchmarkWrap(b, wrapRecursive) })
+}
+
+func TestWrapReverseOrder(t *testing.T) {
+	var calls []string
+	handler := func(w http.ResponseWriter, r *http.Request) error {
+		calls = append(calls, "handler")
+		return nil
+	}
+	mw := []Middleware{record(&calls, "a"), record(&calls, "b"), record(&calls, "c")}
+
+	serve(t, WrapReverse(handler, mw...))
+	if want := []string{"c", "b", "a", "handler"}; !slices.Equal(calls, want) {
+		t.Errorf("WrapReverse calls = %v, want %v", calls, want)
+	}
+
+	calls = nil
+	serve(t, Wrap(handler, mw...))
+	if want := []string{"a", "b", "c", "handler"}; !slices.Equal(calls, want) {
+		t.Errorf("Wrap calls = %v, want %v", calls, want)
+	}
+}
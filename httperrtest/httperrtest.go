@@ -0,0 +1,41 @@
+// Package httperrtest provides utilities for testing httperr handlers.
+package httperrtest
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/kevinfalting/httperr"
+)
+
+// ServeRecord serves r with h against an [httptest.ResponseRecorder], with
+// errors rendered by [httperr.HandleErr] using the default [httperr.ErrFunc].
+// It returns the recorded status and body, and the error h returned.
+func ServeRecord(h httperr.Handler, r *http.Request) (status int, body []byte, err error) {
+	rec := httptest.NewRecorder()
+	capture := httperr.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		err = h.ServeHTTP(w, r)
+		return err
+	})
+
+	httperr.HandleErr(io.Discard, nil)(capture).ServeHTTP(rec, r)
+	return rec.Code, rec.Body.Bytes(), err
+}
+
+// ServeRecordWrap is like [ServeRecord] with a set of [httperr.Middleware]
+// wrapped around h.
+func ServeRecordWrap(h httperr.HandlerFunc, r *http.Request, mw ...httperr.Middleware) (status int, body []byte, err error) {
+	return ServeRecord(httperr.Wrap(h, mw...), r)
+}
+
+// CaptureErr returns an [httperr.ToStd] that stores the error returned by a
+// [httperr.Handler] in dst instead of writing a response, so that tests can
+// assert on the error separately from how it would be rendered.
+func CaptureErr(dst *error) httperr.ToStd {
+	return func(h httperr.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			*dst = h.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package httperr
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// defaultDecodeMaxBytes is the default limit on the size of a request body
+// read by [DecodeJSON].
+const defaultDecodeMaxBytes = 1 << 20
+
+// DecodeOption configures [DecodeJSON].
+type DecodeOption func(*decodeConfig)
+
+type decodeConfig struct {
+	maxBytes        int64
+	disallowUnknown bool
+}
+
+// DecodeMaxBytes limits the request body to n bytes. It defaults to 1MiB.
+func DecodeMaxBytes(n int64) DecodeOption {
+	return func(c *decodeConfig) {
+		c.maxBytes = n
+	}
+}
+
+// DisallowUnknownFields rejects objects with keys that don't match a field of
+// the destination, as with [json.Decoder.DisallowUnknownFields].
+func DisallowUnknownFields() DecodeOption {
+	return func(c *decodeConfig) {
+		c.disallowUnknown = true
+	}
+}
+
+// DecodeJSON decodes a single JSON value from the request body into a T. An
+// empty body, malformed JSON, or data after the value results in a 400 error,
+// and a body over the size limit in a 413 error.
+func DecodeJSON[T any](r *http.Request, opts ...DecodeOption) (T, error) {
+	cfg := decodeConfig{maxBytes: defaultDecodeMaxBytes}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	var v T
+	body := r.Body
+	if body == nil {
+		body = http.NoBody
+	}
+
+	dec := json.NewDecoder(http.MaxBytesReader(nil, body, cfg.maxBytes))
+	if cfg.disallowUnknown {
+		dec.DisallowUnknownFields()
+	}
+
+	if err := dec.Decode(&v); err != nil {
+		if errors.Is(err, io.EOF) {
+			return v, NewError(err, http.StatusBadRequest, WithMessage("request body is empty"))
+		}
+		return v, decodeError(err, "invalid JSON")
+	}
+
+	if err := dec.Decode(&struct{}{}); !errors.Is(err, io.EOF) {
+		if err == nil {
+			err = errors.New("unexpected data after JSON value")
+		}
+		return v, decodeError(err, "invalid JSON: unexpected data after value")
+	}
+
+	return v, nil
+}
+
+func decodeError(err error, msg string) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return NewError(err, http.StatusRequestEntityTooLarge)
+	}
+
+	return NewError(err, http.StatusBadRequest, WithMessage(msg))
+}
+
+// WriteJSON writes v as a JSON response with the status code. The value is
+// encoded before anything is written, so an encoding failure results in a
+// 500 error with no partial response, and handlers can return the result
+// directly.
+func WriteJSON(w http.ResponseWriter, status int, v any) error {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(v); err != nil {
+		return NewError(fmt.Errorf("encoding JSON response: %w", err), http.StatusInternalServerError)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		return NewError(fmt.Errorf("writing JSON response: %w", err), http.StatusInternalServerError)
+	}
+
+	return nil
+}
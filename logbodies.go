@@ -0,0 +1,177 @@
+package httperr
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// BodyLogOptions configures the [LogBodies] middleware.
+type BodyLogOptions struct {
+	// Logger receives a record for each request at [slog.LevelDebug]. It
+	// defaults to [slog.Default].
+	Logger *slog.Logger
+
+	// MaxBytes is the number of bytes of each body that are logged. It
+	// defaults to 64KiB.
+	MaxBytes int
+
+	// Redact is the list of JSON object keys, matched case insensitively at
+	// any depth, whose values are replaced with "***".
+	Redact []string
+}
+
+// LogBodies returns a [Middleware] that logs the request and response bodies
+// of each request, up to a size cap, with the JSON fields in opts.Redact
+// redacted. When Redact is set, bodies that can't be redacted because they
+// aren't valid JSON, including those cut off by the cap, are omitted.
+//
+// LogBodies is meant for debugging only. It buffers the start of every body
+// and parses it to redact it, adding overhead and memory to every request,
+// and bodies often hold sensitive data that Redact doesn't know about. The
+// error response written by [HandleErr] happens outside of the chain, so only
+// the error itself is logged for it.
+func LogBodies(opts BodyLogOptions) Middleware {
+	logger := opts.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	maxBytes := opts.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = 64 << 10
+	}
+
+	redact := make(map[string]bool, len(opts.Redact))
+	for _, key := range opts.Redact {
+		redact[strings.ToLower(key)] = true
+	}
+
+	return Named("httperr.LogBodies", func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			reqBody := bodyBuffer{max: maxBytes}
+			if r.Body != nil && r.Body != http.NoBody {
+				// One byte past the cap detects truncation without logging it.
+				// The read error is returned as is, so that an outer layer such
+				// as [MaxBodySize] can still give it a status.
+				if _, err := reqBody.buf.ReadFrom(io.LimitReader(r.Body, int64(maxBytes)+1)); err != nil {
+					return err
+				}
+				reqBody.truncated = reqBody.buf.Len() > maxBytes
+				r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(reqBody.buf.Bytes()), r.Body))
+			}
+
+			rw := NewResponseWriter(w)
+			tw := &teeWriter{ResponseWriter: rw, body: bodyBuffer{max: maxBytes}}
+			err := next.ServeHTTP(tw, r)
+
+			attrs := []slog.Attr{
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.Int("status", responseStatus(rw, err)),
+				slog.String("request_body", reqBody.redacted(redact)),
+				slog.String("response_body", tw.body.redacted(redact)),
+			}
+			if err != nil {
+				attrs = append(attrs, slog.String("error", err.Error()))
+			}
+
+			logger.LogAttrs(r.Context(), slog.LevelDebug, "bodies", attrs...)
+			return err
+		})
+	})
+}
+
+// bodyBuffer keeps the first max bytes written to it and records whether any
+// more were written.
+type bodyBuffer struct {
+	buf       bytes.Buffer
+	max       int
+	truncated bool
+}
+
+func (b *bodyBuffer) Write(p []byte) (int, error) {
+	n := len(p)
+	if room := b.max - b.buf.Len(); n > room {
+		b.truncated = true
+		p = p[:room]
+	}
+
+	b.buf.Write(p)
+	return n, nil
+}
+
+// redacted returns the body for logging with the keys in redact replaced.
+func (b *bodyBuffer) redacted(redact map[string]bool) string {
+	body := b.buf.Bytes()
+	if len(body) > b.max {
+		body = body[:b.max]
+	}
+
+	if len(redact) == 0 {
+		if b.truncated {
+			return string(body) + "...(truncated)"
+		}
+		return string(body)
+	}
+
+	if len(body) == 0 {
+		return ""
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var v any
+	if b.truncated || dec.Decode(&v) != nil || dec.More() {
+		return "(omitted, not redactable JSON)"
+	}
+
+	out, err := json.Marshal(redactJSON(v, redact))
+	if err != nil {
+		return "(omitted, not redactable JSON)"
+	}
+
+	return string(out)
+}
+
+// redactJSON replaces the values of the object keys in redact, at any depth of
+// v.
+func redactJSON(v any, redact map[string]bool) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if redact[strings.ToLower(key)] {
+				v[key] = "***"
+				continue
+			}
+			v[key] = redactJSON(value, redact)
+		}
+	case []any:
+		for i, value := range v {
+			v[i] = redactJSON(value, redact)
+		}
+	}
+
+	return v
+}
+
+// teeWriter copies the start of the response body into body as it is written.
+type teeWriter struct {
+	http.ResponseWriter
+	body bodyBuffer
+}
+
+func (w *teeWriter) Write(b []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(b)
+	w.body.Write(b[:n])
+	return n, err
+}
+
+// Unwrap returns the underlying [http.ResponseWriter] for use with
+// [http.ResponseController].
+func (w *teeWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
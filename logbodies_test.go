@@ -0,0 +1,24 @@
+package httperr
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLogBodiesBehindMaxBodySize(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	h := HandleErr(io.Discard, nil)(Wrap(func(w http.ResponseWriter, r *http.Request) error {
+		return nil
+	}, MaxBodySize(4), LogBodies(BodyLogOptions{Logger: logger})))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader("too large")))
+
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusRequestEntityTooLarge)
+	}
+}
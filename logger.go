@@ -0,0 +1,120 @@
+package httperr
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+)
+
+// Logger returns a [Middleware] that writes a line to w for every request with
+// the method, path, status and duration, and the error if the next [Handler]
+// returned one. The status of an error is the one [HandleErr] would respond
+// with. The error is returned unchanged so that it is still handled.
+func Logger(w io.Writer) Middleware {
+	return Named("httperr.Logger", func(next Handler) Handler {
+		return HandlerFunc(func(rw http.ResponseWriter, r *http.Request) error {
+			start := startTime(r)
+			recorder := NewResponseWriter(rw)
+			err := next.ServeHTTP(recorder, r)
+			duration := since(start)
+			status := responseStatus(recorder, err)
+
+			if err != nil {
+				fmt.Fprintf(w, "%s %s %d %s error=%s\n", r.Method, r.URL.Path, status, duration, err)
+				return err
+			}
+
+			fmt.Fprintf(w, "%s %s %d %s\n", r.Method, r.URL.Path, status, duration)
+			return nil
+		})
+	})
+}
+
+// LoggerSlog returns a [Middleware] like [Logger] that logs each request to
+// logger at [slog.LevelInfo] with structured attributes. A nil logger defaults
+// to [slog.Default].
+func LoggerSlog(logger *slog.Logger) Middleware {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return Named("httperr.LoggerSlog", func(next Handler) Handler {
+		return HandlerFunc(func(rw http.ResponseWriter, r *http.Request) error {
+			start := startTime(r)
+			recorder := NewResponseWriter(rw)
+			err := next.ServeHTTP(recorder, r)
+			duration := since(start)
+
+			attrs := []slog.Attr{
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.Int("status", responseStatus(recorder, err)),
+				slog.Duration("duration", duration),
+			}
+			if err != nil {
+				attrs = append(attrs, slog.String("error", err.Error()))
+			}
+
+			logger.LogAttrs(r.Context(), slog.LevelInfo, "request", attrs...)
+			return err
+		})
+	})
+}
+
+// responseStatus returns the status code of the response recorded by rw, or
+// the status [HandleErr] will respond with for err if nothing was written.
+func responseStatus(rw *ResponseWriter, err error) int {
+	if rw.Written() {
+		return rw.Status()
+	}
+
+	if err == nil {
+		return http.StatusOK
+	}
+
+	if status, ok := StatusOf(err); ok {
+		return status
+	}
+
+	return http.StatusInternalServerError
+}
+
+type loggerKey struct{}
+
+// WithLogger returns a [Middleware] that stores a child of base, with the
+// request method, path and request ID as attributes, in the request context.
+// It should be placed after [RequestID] for the ID to be included. A nil base
+// defaults to [slog.Default].
+func WithLogger(base *slog.Logger) Middleware {
+	return Named("httperr.WithLogger", func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			logger := base
+			if logger == nil {
+				logger = slog.Default()
+			}
+
+			logger = logger.With(
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+			)
+			if id := RequestIDFromContext(r.Context()); id != "" {
+				logger = logger.With(slog.String("request_id", id))
+			}
+
+			ctx := context.WithValue(r.Context(), loggerKey{}, logger)
+			return next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	})
+}
+
+// LoggerFromContext returns the logger stored by [WithLogger], or
+// [slog.Default] if there is none.
+func LoggerFromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+		return logger
+	}
+
+	return slog.Default()
+}
@@ -0,0 +1,24 @@
+package httperr
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestLoggerDuration(t *testing.T) {
+	clock := setFakeClock(t)
+
+	var buf bytes.Buffer
+	h := Logger(&buf)(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		clock.advance(250 * time.Millisecond)
+		return nil
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/a", nil))
+
+	if got, want := buf.String(), "GET /a 200 250ms\n"; got != want {
+		t.Errorf("log = %q, want %q", got, want)
+	}
+}
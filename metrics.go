@@ -0,0 +1,40 @@
+package httperr
+
+import (
+	"net/http"
+	"time"
+)
+
+// MetricsSink receives an observation for every request handled by the
+// [Metrics] middleware.
+type MetricsSink interface {
+	Observe(route string, status int, dur time.Duration)
+}
+
+// NopMetricsSink is a [MetricsSink] that discards all observations.
+type NopMetricsSink struct{}
+
+// Observe satisfies the [MetricsSink] interface.
+func (NopMetricsSink) Observe(string, int, time.Duration) {}
+
+// Metrics returns a [Middleware] that reports the route, status and duration
+// of every request to sink. The status is taken from the returned error as
+// [HandleErr] would respond with, or from the response when no error was
+// returned. The route is the one recorded for [Route], or the
+// [http.ServeMux] pattern that matched the request, or "unmatched". A nil
+// sink defaults to [NopMetricsSink].
+func Metrics(sink MetricsSink) Middleware {
+	if sink == nil {
+		sink = NopMetricsSink{}
+	}
+
+	return Named("httperr.Metrics", func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			start := startTime(r)
+			rw := NewResponseWriter(w)
+			err := next.ServeHTTP(rw, r)
+			sink.Observe(routeLabel(r), responseStatus(rw, err), since(start))
+			return err
+		})
+	})
+}
@@ -0,0 +1,37 @@
+package httperr
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// sinkFunc adapts a function to a [MetricsSink].
+type sinkFunc func(route string, status int, dur time.Duration)
+
+func (f sinkFunc) Observe(route string, status int, dur time.Duration) {
+	f(route, status, dur)
+}
+
+func TestMetricsDuration(t *testing.T) {
+	clock := setFakeClock(t)
+
+	var got time.Duration
+	sink := sinkFunc(func(_ string, _ int, dur time.Duration) { got = dur })
+
+	// The start time is taken by StartTime, before the time spent in other
+	// middleware.
+	h := Wrap(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		clock.advance(time.Second)
+		return nil
+	}), StartTime(), Before(func(r *http.Request) error {
+		clock.advance(time.Second)
+		return nil
+	}), Metrics(sink))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if want := 2 * time.Second; got != want {
+		t.Errorf("duration = %s, want %s", got, want)
+	}
+}
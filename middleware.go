@@ -0,0 +1,110 @@
+package httperr
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// Skip returns a [Middleware] that applies mw only to requests for which match
+// returns false. Matching requests go straight to the next [Handler].
+func Skip(mw Middleware, match func(*http.Request) bool) Middleware {
+	return Named("httperr.Skip", func(next Handler) Handler {
+		wrapped := mw(next)
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			if match(r) {
+				return next.ServeHTTP(w, r)
+			}
+
+			return wrapped.ServeHTTP(w, r)
+		})
+	})
+}
+
+// PathPrefix returns a matcher for [Skip] that matches requests whose path
+// starts with prefix.
+func PathPrefix(prefix string) func(*http.Request) bool {
+	return func(r *http.Request) bool {
+		return strings.HasPrefix(r.URL.Path, prefix)
+	}
+}
+
+// AllowMethods returns a [Middleware] that returns a 405 error, with the Allow
+// header listing methods, for requests with any other method. Unless OPTIONS
+// is one of methods, OPTIONS requests are answered with a 204 and the Allow
+// header without calling the next [Handler].
+func AllowMethods(methods ...string) Middleware {
+	handleOptions := !slices.Contains(methods, http.MethodOptions)
+	allowed := methods
+	if handleOptions {
+		allowed = append(methods[:len(methods):len(methods)], http.MethodOptions)
+	}
+	allow := strings.Join(allowed, ", ")
+
+	return Named("httperr.AllowMethods", func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			if slices.Contains(methods, r.Method) {
+				return next.ServeHTTP(w, r)
+			}
+
+			if handleOptions && r.Method == http.MethodOptions {
+				w.Header().Set("Allow", allow)
+				w.WriteHeader(http.StatusNoContent)
+				return nil
+			}
+
+			return NewError(nil, http.StatusMethodNotAllowed, WithHeader("Allow", allow))
+		})
+	})
+}
+
+// StripTrailingSlash returns a [Middleware] that permanently redirects
+// requests for paths ending in a slash, other than the root, to the path
+// without it. The redirect is a 308 so that the method and body are
+// preserved.
+func StripTrailingSlash() Middleware {
+	return Named("httperr.StripTrailingSlash", func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			path := r.URL.Path
+			if path == "/" || !strings.HasSuffix(path, "/") {
+				return next.ServeHTTP(w, r)
+			}
+
+			// The escaped path keeps an encoded "?" or "/" from changing the
+			// meaning of the target.
+			target := "/" + strings.Trim(r.URL.EscapedPath(), "/")
+			if r.URL.RawQuery != "" {
+				target += "?" + r.URL.RawQuery
+			}
+
+			http.Redirect(w, r, target, http.StatusPermanentRedirect)
+			return nil
+		})
+	})
+}
+
+// Before returns a [Middleware] that calls fn before the next [Handler]. If fn
+// returns an error, it is returned without calling the next [Handler].
+func Before(fn func(*http.Request) error) Middleware {
+	return Named("httperr.Before", func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			if err := fn(r); err != nil {
+				return err
+			}
+
+			return next.ServeHTTP(w, r)
+		})
+	})
+}
+
+// After returns a [Middleware] that calls fn with the error returned by the
+// next [Handler], which may be nil. The error is returned unchanged.
+func After(fn func(r *http.Request, err error)) Middleware {
+	return Named("httperr.After", func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			err := next.ServeHTTP(w, r)
+			fn(r, err)
+			return err
+		})
+	})
+}
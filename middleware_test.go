@@ -0,0 +1,35 @@
+package httperr
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStripTrailingSlash(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "/users/", want: "/users"},
+		{path: "/users/?page=2", want: "/users?page=2"},
+		{path: "/foo%3Fbar/", want: "/foo%3Fbar"},
+		{path: "/a%2Fb/", want: "/a%2Fb"},
+	}
+
+	h := HandleErr(nil, nil)(StripTrailingSlash()(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return nil
+	})))
+
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+		if rec.Code != http.StatusPermanentRedirect {
+			t.Errorf("%s: status = %d, want %d", tt.path, rec.Code, http.StatusPermanentRedirect)
+		}
+		if got := rec.Header().Get("Location"); got != tt.want {
+			t.Errorf("%s: Location = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package httperr
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// Handle wraps a set of [Middleware] around h, converts it with toStd, and
+// registers it on mux for pattern. A nil toStd uses the default set by
+// [SetDefaultToStd].
+func Handle(mux *http.ServeMux, pattern string, h Handler, toStd ToStd, mw ...Middleware) {
+	mux.Handle(pattern, toStd.orDefault()(recordRoute(WrapHandler(h, mw...))))
+}
+
+// HandleFunc is like [Handle] for a [HandlerFunc].
+func HandleFunc(mux *http.ServeMux, pattern string, h HandlerFunc, toStd ToStd, mw ...Middleware) {
+	Handle(mux, pattern, h, toStd, mw...)
+}
+
+// Group registers routes on an [http.ServeMux] under a shared path prefix with
+// a shared set of [Middleware].
+type Group struct {
+	mux    *http.ServeMux
+	prefix string
+	toStd  ToStd
+	mw     []Middleware
+}
+
+// NewGroup returns a [Group] that registers routes on mux under prefix,
+// wrapped with mw and converted with toStd. A nil toStd uses the default set
+// by [SetDefaultToStd].
+func NewGroup(mux *http.ServeMux, prefix string, toStd ToStd, mw ...Middleware) *Group {
+	// mw is copied, so that Use can't append into the caller's slice.
+	return &Group{
+		mux:    mux,
+		prefix: strings.TrimSuffix(prefix, "/"),
+		toStd:  toStd,
+		mw:     slices.Clone(mw),
+	}
+}
+
+// Use adds mw to the [Middleware] of the group. It only applies to routes
+// registered afterwards.
+func (g *Group) Use(mw ...Middleware) {
+	g.mw = append(g.mw, mw...)
+}
+
+// Handle registers h for the pattern under the group's prefix. The group's
+// [Middleware] are invoked first, followed by mw. The pattern can include a
+// method and host, as with [http.ServeMux], such as "GET /users/{id}".
+func (g *Group) Handle(pattern string, h HandlerFunc, mw ...Middleware) {
+	handler := WrapCommonHandler(g.mw...)(h, mw...)
+	g.mux.Handle(g.pattern(pattern), g.toStd.orDefault()(recordRoute(handler)))
+}
+
+// pattern inserts the group's prefix before the path of pattern.
+func (g *Group) pattern(pattern string) string {
+	method, rest, ok := strings.Cut(pattern, " ")
+	if !ok {
+		method, rest = "", pattern
+	} else {
+		method += " "
+		rest = strings.TrimLeft(rest, " \t")
+	}
+
+	i := strings.Index(rest, "/")
+	if i < 0 {
+		return pattern
+	}
+
+	return method + rest[:i] + g.prefix + rest[i:]
+}
@@ -0,0 +1,29 @@
+package httperr
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestGroupsDontShareMiddleware(t *testing.T) {
+	var calls []string
+	common := make([]Middleware, 0, 4)
+	common = append(common, record(&calls, "common"))
+
+	mux := http.NewServeMux()
+	a := NewGroup(mux, "/a", nil, common...)
+	b := NewGroup(mux, "/b", nil, common...)
+	a.Use(record(&calls, "a"))
+	b.Use(record(&calls, "b"))
+
+	handler := func(w http.ResponseWriter, r *http.Request) error { return nil }
+	a.Handle("/x", handler)
+	b.Handle("/x", handler)
+
+	mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/a/x", nil))
+	if want := []string{"common", "a"}; !slices.Equal(calls, want) {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+}
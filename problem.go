@@ -0,0 +1,72 @@
+package httperr
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// Problem is an RFC 7807 problem details object. Empty fields are given
+// defaults derived from the status code when rendered.
+type Problem struct {
+	Type     string `json:"type,omitempty"`
+	Title    string `json:"title,omitempty"`
+	Status   int    `json:"status,omitempty"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+}
+
+// WithInstance returns a copy of p with Instance set to the request path.
+func (p Problem) WithInstance(r *http.Request) Problem {
+	p.Instance = r.URL.Path
+	return p
+}
+
+func (p Problem) withDefaults(status int) Problem {
+	if p.Type == "" {
+		p.Type = "about:blank"
+	}
+
+	if p.Title == "" {
+		p.Title = http.StatusText(status)
+	}
+
+	p.Status = status
+	return p
+}
+
+// NewProblem returns an error that is rendered to the client as an
+// application/problem+json body built from problem.
+func NewProblem(status int, problem Problem) error {
+	problem = problem.withDefaults(status)
+
+	msg := problem.Title
+	if problem.Detail != "" {
+		msg = problem.Detail
+	}
+
+	body, _ := json.Marshal(problem)
+	return NewError(
+		errors.New(msg),
+		status,
+		WithMessage(msg),
+		WithResponseBody(body, "application/problem+json"),
+	)
+}
+
+// ProblemJSONErrFunc is an [ErrFunc] that writes the error as an RFC 7807
+// application/problem+json body, using err as the detail.
+func ProblemJSONErrFunc(w http.ResponseWriter, err string, code int) {
+	problem := Problem{}.withDefaults(code)
+	if err != problem.Title {
+		problem.Detail = err
+	}
+
+	setContentType(w.Header(), "application/problem+json")
+	w.WriteHeader(code)
+	if !bodyAllowed(code) {
+		return
+	}
+
+	json.NewEncoder(w).Encode(problem)
+}
@@ -0,0 +1,121 @@
+package httperr
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrRateLimited is wrapped by the error returned from [RateLimit] when a
+// request is not allowed.
+var ErrRateLimited = errors.New("rate limited")
+
+// Limiter decides whether a request is allowed, and if not, how long the
+// client should wait before retrying.
+type Limiter interface {
+	Allow(r *http.Request) (ok bool, retryAfter time.Duration)
+}
+
+// RateLimit returns a [Middleware] that returns a 429 error wrapping
+// [ErrRateLimited], with the Retry-After header set, for requests that
+// limiter doesn't allow.
+func RateLimit(limiter Limiter) Middleware {
+	return Named("httperr.RateLimit", func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			ok, retryAfter := limiter.Allow(r)
+			if !ok {
+				return NewError(
+					ErrRateLimited,
+					http.StatusTooManyRequests,
+					WithMessage("rate limit exceeded"),
+					WithRetryAfter(retryAfter),
+				)
+			}
+
+			return next.ServeHTTP(w, r)
+		})
+	})
+}
+
+// TokenBucket is a [Limiter] that keeps a token bucket per client IP address,
+// taken from the request's RemoteAddr. Each request takes a token, and tokens
+// are refilled at a constant rate up to the burst size.
+type TokenBucket struct {
+	rate  float64
+	burst float64
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastPrune time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewTokenBucket returns a [TokenBucket] that allows rate requests per second
+// for each client, with bursts of up to burst requests.
+func NewTokenBucket(rate float64, burst int) *TokenBucket {
+	return &TokenBucket{
+		rate:    rate,
+		burst:   float64(burst),
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// Allow satisfies the [Limiter] interface.
+func (tb *TokenBucket) Allow(r *http.Request) (bool, time.Duration) {
+	key := clientIP(r)
+	t := now()
+
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	tb.prune(t)
+
+	b, ok := tb.buckets[key]
+	if !ok {
+		b = &bucket{tokens: tb.burst, last: t}
+		tb.buckets[key] = b
+	}
+
+	b.tokens = tb.refill(b, t)
+	b.last = t
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+
+	return false, time.Duration((1 - b.tokens) / tb.rate * float64(time.Second))
+}
+
+func (tb *TokenBucket) refill(b *bucket, now time.Time) float64 {
+	return min(tb.burst, b.tokens+now.Sub(b.last).Seconds()*tb.rate)
+}
+
+// prune removes the buckets that have refilled, at most once a minute, to
+// keep the number of tracked clients bounded.
+func (tb *TokenBucket) prune(now time.Time) {
+	if now.Sub(tb.lastPrune) < time.Minute {
+		return
+	}
+
+	tb.lastPrune = now
+	for key, b := range tb.buckets {
+		if tb.refill(b, now) >= tb.burst {
+			delete(tb.buckets, key)
+		}
+	}
+}
+
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
@@ -0,0 +1,110 @@
+package httperr
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func requestFrom(addr string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = addr
+	return r
+}
+
+func TestTokenBucket(t *testing.T) {
+	clock := setFakeClock(t)
+	tb := NewTokenBucket(1, 2)
+	r := requestFrom("192.0.2.1:1234")
+
+	allow := func(wantOK bool, wantRetry time.Duration) {
+		t.Helper()
+		ok, retry := tb.Allow(r)
+		if ok != wantOK || retry != wantRetry {
+			t.Errorf("Allow = %t, %s, want %t, %s", ok, retry, wantOK, wantRetry)
+		}
+	}
+
+	// A new client gets the whole burst.
+	allow(true, 0)
+	allow(true, 0)
+	allow(false, time.Second)
+
+	clock.advance(500 * time.Millisecond)
+	allow(false, 500*time.Millisecond)
+
+	clock.advance(500 * time.Millisecond)
+	allow(true, 0)
+
+	// Refilling stops at the burst size.
+	clock.advance(time.Hour)
+	allow(true, 0)
+	allow(true, 0)
+	allow(false, time.Second)
+
+	// Clients have separate buckets.
+	if ok, _ := tb.Allow(requestFrom("192.0.2.2:1234")); !ok {
+		t.Error("another client was limited")
+	}
+}
+
+func TestTokenBucketPrune(t *testing.T) {
+	clock := setFakeClock(t)
+	tb := NewTokenBucket(1, 1)
+
+	tb.Allow(requestFrom("192.0.2.1:1234"))
+	clock.advance(30 * time.Second)
+	tb.Allow(requestFrom("192.0.2.2:1234"))
+
+	// The first bucket has refilled, but pruning runs at most once a minute.
+	if len(tb.buckets) != 2 {
+		t.Errorf("buckets = %d, want 2", len(tb.buckets))
+	}
+
+	clock.advance(31 * time.Second)
+	tb.Allow(requestFrom("192.0.2.3:1234"))
+
+	if _, ok := tb.buckets["192.0.2.1"]; ok {
+		t.Error("refilled bucket was not pruned")
+	}
+	if len(tb.buckets) != 1 {
+		t.Errorf("buckets = %d, want 1", len(tb.buckets))
+	}
+}
+
+// limiterFunc adapts a function to a [Limiter].
+type limiterFunc func(r *http.Request) (bool, time.Duration)
+
+func (f limiterFunc) Allow(r *http.Request) (bool, time.Duration) {
+	return f(r)
+}
+
+func TestRateLimit(t *testing.T) {
+	limiter := limiterFunc(func(r *http.Request) (bool, time.Duration) {
+		return false, 3 * time.Second
+	})
+
+	mw := RateLimit(limiter)
+	next := HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		t.Error("limited request was served")
+		return nil
+	})
+
+	err := mw(next).ServeHTTP(httptest.NewRecorder(), requestFrom("192.0.2.1:1234"))
+	if !errors.Is(err, ErrRateLimited) {
+		t.Errorf("err = %v, want %v", err, ErrRateLimited)
+	}
+
+	rec := httptest.NewRecorder()
+	HandleErr(io.Discard, nil)(mw(next)).ServeHTTP(rec, requestFrom("192.0.2.1:1234"))
+
+	if rec.Code != http.StatusTooManyRequests {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusTooManyRequests)
+	}
+	if got := rec.Header().Get("Retry-After"); got != "3" {
+		t.Errorf("Retry-After = %q, want %q", got, "3")
+	}
+}
@@ -0,0 +1,54 @@
+package httperr
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Recover returns a [Middleware] that recovers from panics in the next
+// [Handler] and returns them as a 500 error whose stack trace starts where the
+// panic was raised, unless disabled with [SetStackTraces]. The stack trace is
+// logged but never sent to the client. A panic with [http.ErrAbortHandler]
+// is re-panicked so that the server aborts the response.
+func Recover() Middleware {
+	return Named("httperr.Recover", RecoverFunc(func(r *http.Request, v any) error {
+		return NewError(
+			fmt.Errorf("panic: %v", v),
+			http.StatusInternalServerError,
+		)
+	}))
+}
+
+// RecoverFunc returns a [Middleware] like [Recover] that uses fn to produce
+// the error returned for a recovered panic.
+func RecoverFunc(fn func(r *http.Request, v any) error) Middleware {
+	return Named("httperr.RecoverFunc", RecoverWith(func(w http.ResponseWriter, r *http.Request, v any) error {
+		return fn(r, v)
+	}))
+}
+
+// RecoverWith returns a [Middleware] like [Recover] that calls handle for a
+// recovered panic, for routes that need to respond to panics differently. It
+// may write the response itself, and the error it returns is handled as
+// usual. Take care not to leak stack traces to clients outside of
+// development.
+func RecoverWith(handle func(w http.ResponseWriter, r *http.Request, v any) error) Middleware {
+	return Named("httperr.RecoverWith", func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) (err error) {
+			defer func() {
+				v := recover()
+				if v == nil {
+					return
+				}
+
+				if v == http.ErrAbortHandler {
+					panic(v)
+				}
+
+				err = handle(w, r, v)
+			}()
+
+			return next.ServeHTTP(w, r)
+		})
+	})
+}
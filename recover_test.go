@@ -0,0 +1,39 @@
+package httperr
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func panicker(w http.ResponseWriter, r *http.Request) error {
+	panic("boom")
+}
+
+func TestRecoverStackTrace(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	h := HandleErrSlog(logger, nil)(Recover()(HandlerFunc(panicker)))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	var entry struct {
+		Error struct {
+			Err string `json:"err"`
+		} `json:"error"`
+		Stack string `json:"stack"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("log = %q: %v", buf.String(), err)
+	}
+
+	if want := "panic: boom"; entry.Error.Err != want {
+		t.Errorf("err = %q, want %q", entry.Error.Err, want)
+	}
+	if want := pkgPrefix + "panicker\n"; !strings.HasPrefix(entry.Stack, want) {
+		t.Errorf("stack = %q, want it to start with %q", entry.Stack, want)
+	}
+}
@@ -0,0 +1,146 @@
+package httperr
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// JSONErrFunc is an [ErrFunc] that writes the error as a JSON object of the
+// form {"error":"<msg>","status":<code>}. No body is written for a 204 or 304.
+// Use [JSONErrRenderer] to include the [Code] of the error.
+func JSONErrFunc(w http.ResponseWriter, err string, code int) {
+	writeJSONErr(w, err, code, "", "")
+}
+
+// JSONErrRenderer is an [ErrRenderer] like [JSONErrFunc] that also includes
+// the [Code] of the error as "code" when it has one, and the request ID as
+// "request_id" when there is one and [WithRequestIDInBody] is used.
+func JSONErrRenderer(w http.ResponseWriter, r *http.Request, status int, msg string) {
+	writeJSONErr(w, msg, status, errorCode(r), bodyRequestID(w, r))
+}
+
+func writeJSONErr(w http.ResponseWriter, err string, status int, code, requestID string) {
+	setContentType(w.Header(), "application/json")
+	w.WriteHeader(status)
+	if !bodyAllowed(status) {
+		return
+	}
+
+	json.NewEncoder(w).Encode(struct {
+		Error     string `json:"error"`
+		Status    int    `json:"status"`
+		Code      string `json:"code,omitempty"`
+		RequestID string `json:"request_id,omitempty"`
+	}{
+		Error:     err,
+		Status:    status,
+		Code:      code,
+		RequestID: requestID,
+	})
+}
+
+type errorCodeKey struct{}
+
+// errorCode returns the [Code] of the error being rendered for r, or an empty
+// string if it has none.
+func errorCode(r *http.Request) string {
+	code, _ := r.Context().Value(errorCodeKey{}).(string)
+	return code
+}
+
+type requestIDInBodyKey struct{}
+
+// bodyRequestID returns the request ID to include in an error body for r, or
+// an empty string unless [WithRequestIDInBody] is used.
+func bodyRequestID(w http.ResponseWriter, r *http.Request) string {
+	if include, _ := r.Context().Value(requestIDInBodyKey{}).(bool); !include {
+		return ""
+	}
+
+	return requestID(w, r)
+}
+
+// setContentType prepares h for an error body of mediaType, with a utf-8
+// charset and MIME sniffing disabled. Every built-in renderer uses it so that
+// error responses have consistent headers.
+func setContentType(h http.Header, mediaType string) {
+	h.Del("Content-Length")
+	h.Set("Content-Type", mediaType+"; charset=utf-8")
+	h.Set("X-Content-Type-Options", "nosniff")
+}
+
+// bodyAllowed reports whether a response with the status code may include a
+// body.
+func bodyAllowed(code int) bool {
+	switch {
+	case code >= 100 && code <= 199:
+		return false
+	case code == http.StatusNoContent, code == http.StatusNotModified:
+		return false
+	}
+	return true
+}
+
+// NegotiateErrFunc is an [ErrRenderer] that writes the error in the format
+// preferred by the request's Accept header: [JSONErrRenderer] for
+// application/json, [HTMLErrFunc] for text/html, and [http.Error] otherwise.
+func NegotiateErrFunc(w http.ResponseWriter, r *http.Request, status int, msg string) {
+	switch negotiate(r.Header.Get("Accept"), "text/plain", "application/json", "text/html") {
+	case "application/json":
+		JSONErrRenderer(w, r, status, msg)
+	case "text/html":
+		HTMLErrFunc(w, r, status, msg)
+	default:
+		http.Error(w, msg, status)
+	}
+}
+
+// negotiate returns the offer with the highest quality in the accept header.
+// Ties go to the offer listed first, and the first offer is returned when none
+// are acceptable.
+func negotiate(accept string, offers ...string) string {
+	best, bestQ := offers[0], 0.0
+	for _, offer := range offers {
+		if q := quality(accept, offer); q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	return best
+}
+
+// quality returns the quality value of the most specific media range in the
+// accept header that matches offer.
+func quality(accept, offer string) float64 {
+	offerType, _, _ := strings.Cut(offer, "/")
+	q, specificity := 0.0, -1
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		s := -1
+		switch {
+		case mediaType == offer:
+			s = 2
+		case mediaType == offerType+"/*":
+			s = 1
+		case mediaType == "*/*":
+			s = 0
+		}
+		if s <= specificity {
+			continue
+		}
+
+		specificity, q = s, 1
+		if v, ok := params["q"]; ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+	}
+	return q
+}
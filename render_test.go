@@ -0,0 +1,41 @@
+package httperr
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestJSONErrRendererCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{err: NewError(nil, http.StatusNotFound, WithCode("user.not_found")), want: "user.not_found"},
+		{err: NewError(nil, http.StatusNotFound), want: ""},
+	}
+
+	for _, tt := range tests {
+		h := HandleErrWithRequest(io.Discard, JSONErrRenderer)(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			return tt.err
+		}))
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		var body map[string]any
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatalf("body %q: %v", rec.Body.String(), err)
+		}
+
+		code, ok := body["code"]
+		if tt.want == "" && ok {
+			t.Errorf("code = %v, want none", code)
+		}
+		if tt.want != "" && code != tt.want {
+			t.Errorf("code = %v, want %q", code, tt.want)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package httperr
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+// RequestIDHeader is the header used to read and write the request ID.
+const RequestIDHeader = "X-Request-ID"
+
+type requestIDKey struct{}
+
+// RequestIDFromContext returns the request ID stored by the [RequestID]
+// middleware, or an empty string if there is none.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// RequestID returns a [Middleware] that reads the request ID from the
+// incoming [RequestIDHeader], generating a random one if it is missing. The
+// ID is stored in the request context, retrievable with
+// [RequestIDFromContext], and set on the response header.
+func RequestID() Middleware {
+	return Named("httperr.RequestID", RequestIDWithGenerator(newRequestID))
+}
+
+// RequestIDWithGenerator returns a [Middleware] like [RequestID] that uses
+// generate to create missing request IDs.
+func RequestIDWithGenerator(generate func() string) Middleware {
+	return Named("httperr.RequestIDWithGenerator", func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			id := r.Header.Get(RequestIDHeader)
+			if id == "" {
+				id = generate()
+			}
+
+			w.Header().Set(RequestIDHeader, id)
+			ctx := context.WithValue(r.Context(), requestIDKey{}, id)
+			return next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	})
+}
+
+// requestID returns the request ID for r. The [RequestID] middleware stores
+// the ID in a derived context, so layers outside of it fall back to the
+// response header that it sets.
+func requestID(w http.ResponseWriter, r *http.Request) string {
+	if id := RequestIDFromContext(r.Context()); id != "" {
+		return id
+	}
+
+	return w.Header().Get(RequestIDHeader)
+}
+
+func newRequestID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,183 @@
+package httperr
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+)
+
+// ResponseWriter wraps an [http.ResponseWriter] and records the status code and
+// number of bytes written, so that middleware can observe the outcome of a
+// request. It passes [http.Flusher], [http.Hijacker] and [io.ReaderFrom]
+// through to the underlying writer, and supports [http.ResponseController]
+// via Unwrap.
+type ResponseWriter struct {
+	http.ResponseWriter
+	status   int
+	written  int64
+	hijacked bool
+}
+
+// NewResponseWriter wraps w in a [ResponseWriter]. If w is already a
+// [*ResponseWriter] it is returned as is, so that every layer of a chain shares
+// the same record of the response.
+func NewResponseWriter(w http.ResponseWriter) *ResponseWriter {
+	if rw, ok := w.(*ResponseWriter); ok {
+		return rw
+	}
+
+	return &ResponseWriter{ResponseWriter: w}
+}
+
+var responseWriterPool = sync.Pool{
+	New: func() any {
+		return new(ResponseWriter)
+	},
+}
+
+// acquireResponseWriter is like [NewResponseWriter], but takes the wrapper
+// from a pool. It reports whether the wrapper must be released with
+// releaseResponseWriter once the response is complete.
+func acquireResponseWriter(w http.ResponseWriter) (*ResponseWriter, bool) {
+	if rw, ok := w.(*ResponseWriter); ok {
+		return rw, false
+	}
+
+	rw := responseWriterPool.Get().(*ResponseWriter)
+	rw.ResponseWriter = w
+	return rw, true
+}
+
+// releaseResponseWriter returns rw to the pool. A hijacked wrapper is never
+// reused, since the connection may still be referenced through it.
+func releaseResponseWriter(rw *ResponseWriter) {
+	if rw.hijacked {
+		return
+	}
+
+	*rw = ResponseWriter{}
+	responseWriterPool.Put(rw)
+}
+
+// WriteHeader records the status code and writes it to the underlying writer.
+// Calls after the response has started are dropped, rather than passed on to
+// be logged by [net/http] as superfluous.
+func (rw *ResponseWriter) WriteHeader(code int) {
+	if rw.status != 0 {
+		return
+	}
+
+	// Informational responses can be followed by the final status.
+	if code >= 200 || code == http.StatusSwitchingProtocols {
+		rw.status = code
+	}
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+// Write records the number of bytes written to the underlying writer.
+func (rw *ResponseWriter) Write(b []byte) (int, error) {
+	if rw.status == 0 {
+		rw.status = http.StatusOK
+	}
+	n, err := rw.ResponseWriter.Write(b)
+	rw.written += int64(n)
+	return n, err
+}
+
+// ReadFrom satisfies the [io.ReaderFrom] interface and records the number of
+// bytes written. It forwards to the ReadFrom of the underlying writer when it
+// has one, so that the sendfile path of [net/http] is kept, and otherwise
+// copies src with [io.Copy].
+func (rw *ResponseWriter) ReadFrom(src io.Reader) (int64, error) {
+	if rw.status == 0 {
+		rw.status = http.StatusOK
+	}
+
+	var n int64
+	var err error
+	if rf, ok := rw.ResponseWriter.(io.ReaderFrom); ok {
+		n, err = rf.ReadFrom(src)
+	} else {
+		n, err = io.Copy(rw.ResponseWriter, src)
+	}
+	rw.written += n
+	return n, err
+}
+
+// Flush satisfies the [http.Flusher] interface. Flushing commits the response
+// with a 200 if no status has been written.
+func (rw *ResponseWriter) Flush() {
+	if rw.status == 0 {
+		rw.status = http.StatusOK
+	}
+	http.NewResponseController(rw.ResponseWriter).Flush()
+}
+
+// Hijack satisfies the [http.Hijacker] interface.
+func (rw *ResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, brw, err := http.NewResponseController(rw.ResponseWriter).Hijack()
+	if err == nil {
+		rw.hijacked = true
+	}
+	return conn, brw, err
+}
+
+// Unwrap returns the underlying [http.ResponseWriter] for use with
+// [http.ResponseController].
+func (rw *ResponseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+// Status returns the status code of the response, or 0 if it hasn't been
+// written.
+func (rw *ResponseWriter) Status() int {
+	return rw.status
+}
+
+// BytesWritten returns the number of body bytes written.
+func (rw *ResponseWriter) BytesWritten() int64 {
+	return rw.written
+}
+
+// Written reports whether the response has been started, either by writing
+// the status code or body, or by hijacking the connection.
+func (rw *ResponseWriter) Written() bool {
+	return rw.status != 0 || rw.hijacked
+}
+
+// bufferedWriter is an [http.ResponseWriter] that holds the whole response in
+// memory, for middleware that need to see it before it's sent.
+type bufferedWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (bw *bufferedWriter) Header() http.Header {
+	return bw.header
+}
+
+func (bw *bufferedWriter) WriteHeader(code int) {
+	// Informational responses can't be buffered meaningfully, so they're
+	// dropped.
+	if bw.status == 0 && code >= 200 {
+		bw.status = code
+	}
+}
+
+func (bw *bufferedWriter) Write(b []byte) (int, error) {
+	if bw.status == 0 {
+		bw.status = http.StatusOK
+	}
+	return bw.body.Write(b)
+}
+
+func (bw *bufferedWriter) statusOrOK() int {
+	if bw.status == 0 {
+		return http.StatusOK
+	}
+	return bw.status
+}
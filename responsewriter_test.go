@@ -0,0 +1,73 @@
+package httperr
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+var sinkResponseWriter *ResponseWriter
+
+func TestReleaseResponseWriterSkipsHijacked(t *testing.T) {
+	rw, _ := acquireResponseWriter(httptest.NewRecorder())
+	rw.hijacked = true
+	releaseResponseWriter(rw)
+
+	if rw.ResponseWriter == nil || !rw.hijacked {
+		t.Error("hijacked wrapper was reset and returned to the pool")
+	}
+}
+
+// readFromRecorder is an [http.ResponseWriter] that records calls to ReadFrom.
+type readFromRecorder struct {
+	*httptest.ResponseRecorder
+	calls int
+}
+
+func (w *readFromRecorder) ReadFrom(src io.Reader) (int64, error) {
+	w.calls++
+	return io.Copy(w.ResponseRecorder, src)
+}
+
+func TestResponseWriterReadFrom(t *testing.T) {
+	w := &readFromRecorder{ResponseRecorder: httptest.NewRecorder()}
+	rw := NewResponseWriter(w)
+
+	// Hide the WriteTo of the reader, so that io.Copy reaches for ReadFrom.
+	n, err := io.Copy(rw, struct{ io.Reader }{strings.NewReader("hello")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if w.calls != 1 {
+		t.Errorf("underlying ReadFrom called %d times, want 1", w.calls)
+	}
+	if n != 5 || rw.BytesWritten() != 5 || w.Body.String() != "hello" {
+		t.Errorf("copied %d, BytesWritten = %d, body = %q, want 5, 5, %q", n, rw.BytesWritten(), w.Body.String(), "hello")
+	}
+	if rw.Status() != http.StatusOK {
+		t.Errorf("status = %d, want %d", rw.Status(), http.StatusOK)
+	}
+}
+
+func BenchmarkResponseWriter(b *testing.B) {
+	w := httptest.NewRecorder()
+
+	b.Run("new", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			sinkResponseWriter = NewResponseWriter(w)
+		}
+	})
+
+	b.Run("pooled", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			rw, _ := acquireResponseWriter(w)
+			sinkResponseWriter = rw
+			releaseResponseWriter(rw)
+		}
+	})
+}
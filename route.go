@@ -0,0 +1,82 @@
+package httperr
+
+import (
+	"context"
+	"net/http"
+)
+
+type routeSlotKey struct{}
+
+type routeSlot struct {
+	route string
+}
+
+// Route returns a [Middleware] that makes the [http.ServeMux] pattern matching
+// the request available with [RouteFromContext], for logging and metrics
+// labels with a low cardinality. The pattern is only known once the request
+// has been routed, so Route can be placed in front of the mux: the route is
+// recorded by handlers registered with [Handle], [HandleFunc] and [Group],
+// or by a Route inside the mux, and the layers between Route and the mux,
+// such as [Metrics], read it after calling the next [Handler].
+func Route() Middleware {
+	return Named("httperr.Route", func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			if _, ok := r.Context().Value(routeSlotKey{}).(*routeSlot); !ok {
+				ctx := context.WithValue(r.Context(), routeSlotKey{}, &routeSlot{})
+				r = r.WithContext(ctx)
+			}
+
+			storeRoute(r)
+			err := next.ServeHTTP(w, r)
+			// The mux sets the pattern on the request it is given, which is r
+			// when nothing in between derived a new request.
+			storeRoute(r)
+			return err
+		})
+	})
+}
+
+// RouteFromContext returns the route recorded in a context prepared by
+// [Route], or "unmatched" if no route was matched or recorded.
+func RouteFromContext(ctx context.Context) string {
+	if slot, _ := ctx.Value(routeSlotKey{}).(*routeSlot); slot != nil && slot.route != "" {
+		return slot.route
+	}
+
+	return "unmatched"
+}
+
+// storeRoute records the pattern that matched r in the route slot of its
+// context, if it has both.
+func storeRoute(r *http.Request) {
+	if r.Pattern == "" {
+		return
+	}
+
+	if slot, _ := r.Context().Value(routeSlotKey{}).(*routeSlot); slot != nil {
+		slot.route = r.Pattern
+	}
+}
+
+// recordRoute is the [Middleware] used by [Handle] and [Group] to record the
+// pattern that matched each request for [Route].
+func recordRoute(next Handler) Handler {
+	return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		storeRoute(r)
+		return next.ServeHTTP(w, r)
+	})
+}
+
+// routeLabel returns the route of r for metrics: the one recorded for [Route],
+// or the pattern that matched r, or "unmatched".
+func routeLabel(r *http.Request) string {
+	if route := RouteFromContext(r.Context()); route != "unmatched" {
+		return route
+	}
+
+	if r.Pattern == "" {
+		return "unmatched"
+	}
+
+	return r.Pattern
+}
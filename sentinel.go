@@ -0,0 +1,59 @@
+package httperr
+
+import (
+	"errors"
+	"slices"
+	"sync"
+)
+
+type sentinelStatus struct {
+	sentinel error
+	status   int
+	msg      string
+}
+
+var (
+	sentinelsMu sync.RWMutex
+	sentinels   []sentinelStatus
+)
+
+// RegisterStatus maps sentinel to a status and client message for
+// [FromSentinel]. Registering a sentinel again replaces its mapping. It is
+// safe for concurrent use, but is meant to be called once for each sentinel
+// at startup, such as from an init function.
+func RegisterStatus(sentinel error, status int, msg string) {
+	sentinelsMu.Lock()
+	defer sentinelsMu.Unlock()
+
+	entry := sentinelStatus{sentinel: sentinel, status: status, msg: msg}
+	i := slices.IndexFunc(sentinels, func(s sentinelStatus) bool {
+		return s.sentinel == sentinel
+	})
+	if i >= 0 {
+		sentinels[i] = entry
+		return
+	}
+
+	sentinels = append(sentinels, entry)
+}
+
+// FromSentinel wraps err as by [NewError] with the status and message of the
+// first sentinel registered with [RegisterStatus] that err matches with
+// [errors.Is]. Otherwise err is returned unchanged. It complements
+// [WithMapper] with a single registry for well-known errors.
+func FromSentinel(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	sentinelsMu.RLock()
+	defer sentinelsMu.RUnlock()
+
+	for _, s := range sentinels {
+		if errors.Is(err, s.sentinel) {
+			return NewError(err, s.status, WithMessage(s.msg))
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,54 @@
+package httperr
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// SSEError writes err to a Server-Sent Events stream as a final frame with
+// the event type "error" and the client message of err as its data, and
+// flushes it. The status and headers of a stream are committed with its first
+// event, so this is the only way left to tell the client about an error. The
+// message is the one from [StatusMsg], or the status text of a 500 for other
+// errors. It returns the error from writing to w.
+//
+// [HandleErr] does this itself for errors returned after a handler has started
+// a response with the Content-Type text/event-stream.
+func SSEError(w http.ResponseWriter, err error) error {
+	msg := http.StatusText(http.StatusInternalServerError)
+	var statusMsg StatusMsg
+	if errors.As(err, &statusMsg) {
+		_, msg = statusMsg.StatusMsg()
+	}
+
+	return writeSSEError(w, msg)
+}
+
+func writeSSEError(w http.ResponseWriter, msg string) error {
+	var b strings.Builder
+	b.WriteString("event: error\n")
+	for _, line := range strings.Split(msg, "\n") {
+		fmt.Fprintf(&b, "data: %s\n", strings.TrimSuffix(line, "\r"))
+	}
+	b.WriteString("\n")
+
+	if _, err := w.Write([]byte(b.String())); err != nil {
+		return err
+	}
+
+	err := http.NewResponseController(w).Flush()
+	if errors.Is(err, http.ErrNotSupported) {
+		return nil
+	}
+	return err
+}
+
+// isEventStream reports whether the response headers h are for a Server-Sent
+// Events stream.
+func isEventStream(h http.Header) bool {
+	mediaType, _, err := mime.ParseMediaType(h.Get("Content-Type"))
+	return err == nil && mediaType == "text/event-stream"
+}
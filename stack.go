@@ -0,0 +1,68 @@
+package httperr
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+const pkgPrefix = "github.com/kevinfalting/httperr."
+
+var captureStack atomic.Bool
+
+func init() {
+	captureStack.Store(true)
+}
+
+// SetStackTraces enables or disables the capture of stack traces for errors
+// with a status of 500 or above. It is enabled by default.
+func SetStackTraces(enabled bool) {
+	captureStack.Store(enabled)
+}
+
+// callers returns the program counters of the calling stack, starting at the
+// first frame outside of this package. The frames of the runtime that follow
+// are skipped too, so that when called while recovering from a panic the stack
+// starts where the panic was raised.
+func callers() []uintptr {
+	var pcs [32]uintptr
+	n := runtime.Callers(2, pcs[:])
+	stack := skipFrames(pcs[:n], pkgPrefix)
+	stack = skipFrames(stack, "runtime.")
+
+	return append([]uintptr(nil), stack...)
+}
+
+// skipFrames returns stack without its leading frames of functions whose name
+// starts with prefix.
+func skipFrames(stack []uintptr, prefix string) []uintptr {
+	for len(stack) > 0 {
+		fn := runtime.FuncForPC(stack[0] - 1)
+		if fn == nil || !strings.HasPrefix(fn.Name(), prefix) {
+			break
+		}
+		stack = stack[1:]
+	}
+	return stack
+}
+
+// formatStack formats the program counters as function names and file
+// locations, one frame per pair of lines.
+func formatStack(stack []uintptr) string {
+	var b strings.Builder
+	frames := runtime.CallersFrames(stack)
+	for {
+		frame, more := frames.Next()
+		b.WriteString(frame.Function)
+		b.WriteString("\n\t")
+		b.WriteString(frame.File)
+		b.WriteByte(':')
+		b.WriteString(strconv.Itoa(frame.Line))
+		if !more {
+			break
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
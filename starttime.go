@@ -0,0 +1,44 @@
+package httperr
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+type startTimeKey struct{}
+
+// StartTimeFromContext returns the request start time stored by the
+// [StartTime] middleware, and whether there is one.
+func StartTimeFromContext(ctx context.Context) (time.Time, bool) {
+	start, ok := ctx.Value(startTimeKey{}).(time.Time)
+	return start, ok
+}
+
+// StartTime returns a [Middleware] that stores the time the request reached it
+// in the request context, retrievable with [StartTimeFromContext]. [Logger],
+// [LoggerSlog] and [Metrics] measure durations from it when they are placed
+// after it. An existing start time is kept, so only the first StartTime in a
+// chain takes effect.
+func StartTime() Middleware {
+	return Named("httperr.StartTime", func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			if _, ok := StartTimeFromContext(r.Context()); ok {
+				return next.ServeHTTP(w, r)
+			}
+
+			ctx := context.WithValue(r.Context(), startTimeKey{}, now())
+			return next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	})
+}
+
+// startTime returns the start time stored by [StartTime] for r, or the current
+// time if there is none.
+func startTime(r *http.Request) time.Time {
+	if start, ok := StartTimeFromContext(r.Context()); ok {
+		return start
+	}
+
+	return now()
+}
@@ -0,0 +1,75 @@
+package httperr
+
+import (
+	"net/http"
+)
+
+// BadRequest wraps err with a 400 Bad Request status.
+func BadRequest(err error, responseMsg ...string) error {
+	return NewErrorMsg(err, http.StatusBadRequest, responseMsg...)
+}
+
+// Unauthorized wraps err with a 401 Unauthorized status.
+func Unauthorized(err error, responseMsg ...string) error {
+	return NewErrorMsg(err, http.StatusUnauthorized, responseMsg...)
+}
+
+// Forbidden wraps err with a 403 Forbidden status.
+func Forbidden(err error, responseMsg ...string) error {
+	return NewErrorMsg(err, http.StatusForbidden, responseMsg...)
+}
+
+// NotFound wraps err with a 404 Not Found status.
+func NotFound(err error, responseMsg ...string) error {
+	return NewErrorMsg(err, http.StatusNotFound, responseMsg...)
+}
+
+// MethodNotAllowed wraps err with a 405 Method Not Allowed status.
+func MethodNotAllowed(err error, responseMsg ...string) error {
+	return NewErrorMsg(err, http.StatusMethodNotAllowed, responseMsg...)
+}
+
+// Conflict wraps err with a 409 Conflict status.
+func Conflict(err error, responseMsg ...string) error {
+	return NewErrorMsg(err, http.StatusConflict, responseMsg...)
+}
+
+// Gone wraps err with a 410 Gone status.
+func Gone(err error, responseMsg ...string) error {
+	return NewErrorMsg(err, http.StatusGone, responseMsg...)
+}
+
+// UnprocessableEntity wraps err with a 422 Unprocessable Entity status.
+func UnprocessableEntity(err error, responseMsg ...string) error {
+	return NewErrorMsg(err, http.StatusUnprocessableEntity, responseMsg...)
+}
+
+// TooManyRequests wraps err with a 429 Too Many Requests status.
+func TooManyRequests(err error, responseMsg ...string) error {
+	return NewErrorMsg(err, http.StatusTooManyRequests, responseMsg...)
+}
+
+// InternalServerError wraps err with a 500 Internal Server Error status.
+func InternalServerError(err error, responseMsg ...string) error {
+	return NewErrorMsg(err, http.StatusInternalServerError, responseMsg...)
+}
+
+// NotImplemented wraps err with a 501 Not Implemented status.
+func NotImplemented(err error, responseMsg ...string) error {
+	return NewErrorMsg(err, http.StatusNotImplemented, responseMsg...)
+}
+
+// BadGateway wraps err with a 502 Bad Gateway status.
+func BadGateway(err error, responseMsg ...string) error {
+	return NewErrorMsg(err, http.StatusBadGateway, responseMsg...)
+}
+
+// ServiceUnavailable wraps err with a 503 Service Unavailable status.
+func ServiceUnavailable(err error, responseMsg ...string) error {
+	return NewErrorMsg(err, http.StatusServiceUnavailable, responseMsg...)
+}
+
+// GatewayTimeout wraps err with a 504 Gateway Timeout status.
+func GatewayTimeout(err error, responseMsg ...string) error {
+	return NewErrorMsg(err, http.StatusGatewayTimeout, responseMsg...)
+}
@@ -0,0 +1,220 @@
+package httperr
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Timeout returns a [Middleware] that cancels the request context after d
+// and returns a 504 error if the next [Handler] hasn't returned by then.
+//
+// The next [Handler] runs in its own goroutine so that the error can be
+// returned as soon as the deadline passes, while the handler may still be
+// running. To avoid concurrent writes, the handler is given a writer that
+// buffers header changes until the status is written, and that refuses all
+// writes with [http.ErrHandlerTimeout] once the deadline has passed. If the
+// handler had already started the response, the error is still returned but
+// the status can't be changed. The handler should stop work when its context
+// is done. Hijacking the connection is not supported. A panic in the handler
+// is re-panicked on the calling goroutine.
+func Timeout(d time.Duration) Middleware {
+	return Named("httperr.Timeout", func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			ctx, cancel := context.WithTimeout(r.Context(), d)
+			defer cancel()
+
+			tw := &timeoutWriter{
+				w:      w,
+				header: w.Header().Clone(),
+			}
+
+			done := make(chan error, 1)
+			panicked := make(chan any, 1)
+			go func() {
+				defer func() {
+					if v := recover(); v != nil {
+						panicked <- v
+					}
+				}()
+				done <- next.ServeHTTP(tw, r.WithContext(ctx))
+			}()
+
+			select {
+			case v := <-panicked:
+				panic(v)
+			case err := <-done:
+				tw.finish()
+				return err
+			case <-ctx.Done():
+				tw.mu.Lock()
+				defer tw.mu.Unlock()
+
+				// The handler may have returned while the deadline passed.
+				select {
+				case err := <-done:
+					tw.finishLocked()
+					return err
+				default:
+				}
+
+				tw.timedOut = true
+				if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+					return ctx.Err()
+				}
+
+				return NewError(ctx.Err(), http.StatusGatewayTimeout)
+			}
+		})
+	})
+}
+
+// timeoutWriter guards an [http.ResponseWriter] from being written to after a
+// timeout. The handler's header changes are kept in header until the status is
+// written, so that the header of w is never touched concurrently.
+type timeoutWriter struct {
+	mu          sync.Mutex
+	w           http.ResponseWriter
+	header      http.Header
+	wroteHeader bool
+	timedOut    bool
+}
+
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.header
+}
+
+func (tw *timeoutWriter) WriteHeader(code int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.timedOut {
+		return
+	}
+
+	tw.writeHeaderLocked(code)
+}
+
+func (tw *timeoutWriter) Write(b []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+
+	if !tw.wroteHeader {
+		tw.writeHeaderLocked(http.StatusOK)
+	}
+
+	return tw.w.Write(b)
+}
+
+func (tw *timeoutWriter) Flush() {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.timedOut {
+		return
+	}
+
+	if !tw.wroteHeader {
+		tw.writeHeaderLocked(http.StatusOK)
+	}
+
+	http.NewResponseController(tw.w).Flush()
+}
+
+func (tw *timeoutWriter) writeHeaderLocked(code int) {
+	tw.copyHeaderLocked()
+	if code >= 200 || code == http.StatusSwitchingProtocols {
+		tw.wroteHeader = true
+	}
+	tw.w.WriteHeader(code)
+}
+
+func (tw *timeoutWriter) copyHeaderLocked() {
+	dst := tw.w.Header()
+	for key := range dst {
+		if _, ok := tw.header[key]; !ok {
+			delete(dst, key)
+		}
+	}
+	for key, values := range tw.header {
+		dst[key] = values
+	}
+}
+
+// finish copies the handler's header changes to w once the handler has
+// returned without writing the status, so that they're kept in the response.
+func (tw *timeoutWriter) finish() {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	tw.finishLocked()
+}
+
+func (tw *timeoutWriter) finishLocked() {
+	if !tw.wroteHeader {
+		tw.copyHeaderLocked()
+	}
+}
+
+// DeadlineResponse returns a [Middleware] that, like [http.TimeoutHandler],
+// runs the next [Handler] with a buffered response and a context that is
+// canceled after d. If the handler returns in time its response is sent,
+// otherwise a 503 error with msg as the message and wrapping
+// [http.ErrHandlerTimeout] is returned, so that it's rendered like any other
+// error. If the handler returns an error, its buffered response is discarded.
+//
+// The whole response is held in memory until the handler returns, so it
+// shouldn't be used for large responses. Flushing and hijacking are not
+// supported, which rules out streaming handlers. A panic in the handler is
+// re-panicked on the calling goroutine.
+func DeadlineResponse(d time.Duration, msg string) Middleware {
+	return Named("httperr.DeadlineResponse", func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			ctx, cancel := context.WithTimeout(r.Context(), d)
+			defer cancel()
+
+			bw := &bufferedWriter{header: w.Header().Clone()}
+			done := make(chan error, 1)
+			panicked := make(chan any, 1)
+			go func() {
+				defer func() {
+					if v := recover(); v != nil {
+						panicked <- v
+					}
+				}()
+				done <- next.ServeHTTP(bw, r.WithContext(ctx))
+			}()
+
+			select {
+			case v := <-panicked:
+				panic(v)
+			case err := <-done:
+				dst := w.Header()
+				clear(dst)
+				for key, values := range bw.header {
+					dst[key] = values
+				}
+
+				if err != nil {
+					return err
+				}
+
+				w.WriteHeader(bw.statusOrOK())
+				w.Write(bw.body.Bytes())
+				return nil
+			case <-ctx.Done():
+				if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+					return ctx.Err()
+				}
+
+				return NewError(http.ErrHandlerTimeout, http.StatusServiceUnavailable, WithMessage(msg))
+			}
+		})
+	})
+}
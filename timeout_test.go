@@ -0,0 +1,193 @@
+package httperr
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// expectPanic fails the test unless fn panics with want.
+func expectPanic(t *testing.T, want any, fn func()) {
+	t.Helper()
+	defer func() {
+		if v := recover(); v != want {
+			t.Errorf("recovered %v, want %v", v, want)
+		}
+	}()
+	fn()
+}
+
+func TestTimeoutInTime(t *testing.T) {
+	h := Timeout(time.Minute)(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("X-Handler", "kept")
+		return nil
+	}))
+
+	rec := httptest.NewRecorder()
+	if err := h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := rec.Header().Get("X-Handler"); got != "kept" {
+		t.Errorf("X-Handler = %q, want %q", got, "kept")
+	}
+}
+
+func TestTimeoutNothingWritten(t *testing.T) {
+	returned := make(chan struct{})
+	late := make(chan struct{})
+	h := HandleErr(io.Discard, nil)(Timeout(time.Millisecond)(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		<-r.Context().Done()
+		<-returned
+		w.Header().Set("X-Late", "late")
+		close(late)
+		return nil
+	})))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	close(returned)
+	<-late
+
+	if rec.Code != http.StatusGatewayTimeout {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusGatewayTimeout)
+	}
+	if got := rec.Header().Get("X-Late"); got != "" {
+		t.Errorf("X-Late = %q, want none", got)
+	}
+}
+
+func TestTimeoutWriteAfterDeadline(t *testing.T) {
+	returned := make(chan struct{})
+	writeErr := make(chan error, 1)
+	h := Timeout(time.Millisecond)(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		<-r.Context().Done()
+		<-returned
+		_, err := io.WriteString(w, "late")
+		writeErr <- err
+		return nil
+	}))
+
+	rec := httptest.NewRecorder()
+	err := h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	close(returned)
+
+	if status, _ := StatusOf(err); status != http.StatusGatewayTimeout {
+		t.Errorf("status of %v = %d, want %d", err, status, http.StatusGatewayTimeout)
+	}
+	if err := <-writeErr; !errors.Is(err, http.ErrHandlerTimeout) {
+		t.Errorf("write err = %v, want %v", err, http.ErrHandlerTimeout)
+	}
+	if rec.Body.Len() != 0 {
+		t.Errorf("body = %q, want none", rec.Body.String())
+	}
+}
+
+func TestTimeoutAfterWrite(t *testing.T) {
+	returned := make(chan struct{})
+	h := Timeout(time.Millisecond)(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		io.WriteString(w, "partial")
+		<-r.Context().Done()
+		<-returned
+		return nil
+	}))
+
+	rec := httptest.NewRecorder()
+	err := h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	close(returned)
+
+	if status, _ := StatusOf(err); status != http.StatusGatewayTimeout {
+		t.Errorf("status of %v = %d, want %d", err, status, http.StatusGatewayTimeout)
+	}
+	if rec.Code != http.StatusOK || rec.Body.String() != "partial" {
+		t.Errorf("response = %d %q, want %d %q", rec.Code, rec.Body.String(), http.StatusOK, "partial")
+	}
+}
+
+func TestTimeoutPanic(t *testing.T) {
+	h := Timeout(time.Minute)(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		panic("boom")
+	}))
+
+	expectPanic(t, "boom", func() {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+}
+
+func TestDeadlineResponseInTime(t *testing.T) {
+	h := DeadlineResponse(time.Minute, "too slow")(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("X-Handler", "kept")
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, "done")
+		return nil
+	}))
+
+	rec := httptest.NewRecorder()
+	if err := h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	if rec.Code != http.StatusCreated || rec.Body.String() != "done" {
+		t.Errorf("response = %d %q, want %d %q", rec.Code, rec.Body.String(), http.StatusCreated, "done")
+	}
+	if got := rec.Header().Get("X-Handler"); got != "kept" {
+		t.Errorf("X-Handler = %q, want %q", got, "kept")
+	}
+}
+
+func TestDeadlineResponseError(t *testing.T) {
+	boom := errors.New("boom")
+	h := DeadlineResponse(time.Minute, "too slow")(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		io.WriteString(w, "discarded")
+		return boom
+	}))
+
+	rec := httptest.NewRecorder()
+	if err := h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil)); err != boom {
+		t.Errorf("err = %v, want %v", err, boom)
+	}
+	if rec.Body.Len() != 0 {
+		t.Errorf("body = %q, want none", rec.Body.String())
+	}
+}
+
+func TestDeadlineResponseTimeout(t *testing.T) {
+	returned := make(chan struct{})
+	h := DeadlineResponse(time.Millisecond, "too slow")(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		<-r.Context().Done()
+		<-returned
+		io.WriteString(w, "late")
+		return nil
+	}))
+
+	rec := httptest.NewRecorder()
+	err := h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	close(returned)
+
+	if !errors.Is(err, http.ErrHandlerTimeout) {
+		t.Errorf("err = %v, want %v", err, http.ErrHandlerTimeout)
+	}
+	var statusMsg StatusMsg
+	if !errors.As(err, &statusMsg) {
+		t.Fatalf("err = %v, want a StatusMsg", err)
+	}
+	if status, msg := statusMsg.StatusMsg(); status != http.StatusServiceUnavailable || msg != "too slow" {
+		t.Errorf("StatusMsg = %d, %q, want %d, %q", status, msg, http.StatusServiceUnavailable, "too slow")
+	}
+	if rec.Body.Len() != 0 {
+		t.Errorf("body = %q, want none", rec.Body.String())
+	}
+}
+
+func TestDeadlineResponsePanic(t *testing.T) {
+	h := DeadlineResponse(time.Minute, "too slow")(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		panic("boom")
+	}))
+
+	expectPanic(t, "boom", func() {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+}
@@ -0,0 +1,56 @@
+package httperr
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// RequireTLS returns a [Middleware] for requests that weren't made over TLS.
+// If redirect is true they are redirected to the same URL with the https
+// scheme on its default port, since the port of the request is that of the
+// plaintext listener, otherwise a 400 error is returned. Only r.TLS is checked, use
+// [RequireTLSBehindProxy] when TLS is terminated by a proxy.
+func RequireTLS(redirect bool) Middleware {
+	return Named("httperr.RequireTLS", requireTLS(redirect, false))
+}
+
+// RequireTLSBehindProxy is like [RequireTLS], but also treats a request as TLS
+// when the X-Forwarded-Proto header is https. The header is set by the client
+// unless a proxy overwrites it, so only use this behind a trusted proxy.
+func RequireTLSBehindProxy(redirect bool) Middleware {
+	return Named("httperr.RequireTLSBehindProxy", requireTLS(redirect, true))
+}
+
+func requireTLS(redirect, trustProxy bool) Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			if r.TLS != nil || trustProxy && strings.EqualFold(r.Header.Get("X-Forwarded-Proto"), "https") {
+				return next.ServeHTTP(w, r)
+			}
+
+			if !redirect {
+				return NewError(nil, http.StatusBadRequest, WithMessage("HTTPS required"))
+			}
+
+			u := *r.URL
+			u.Scheme = "https"
+			u.Host = stripPort(r.Host)
+			http.Redirect(w, r, u.String(), http.StatusPermanentRedirect)
+			return nil
+		})
+	}
+}
+
+// stripPort returns host without its port, if it has one.
+func stripPort(host string) string {
+	h, _, err := net.SplitHostPort(host)
+	if err != nil {
+		return host
+	}
+
+	if strings.Contains(h, ":") {
+		return "[" + h + "]"
+	}
+	return h
+}
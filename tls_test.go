@@ -0,0 +1,35 @@
+package httperr
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequireTLSRedirect(t *testing.T) {
+	tests := []struct {
+		target string
+		want   string
+	}{
+		{"http://example.com/a?b=c", "https://example.com/a?b=c"},
+		{"http://example.com:8080/a?b=c", "https://example.com/a?b=c"},
+		{"http://[::1]:8080/a", "https://[::1]/a"},
+	}
+
+	h := HandleErr(io.Discard, nil)(RequireTLS(true)(HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return nil
+	})))
+
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+		if rec.Code != http.StatusPermanentRedirect {
+			t.Errorf("%s: status = %d, want %d", tt.target, rec.Code, http.StatusPermanentRedirect)
+		}
+		if got := rec.Header().Get("Location"); got != tt.want {
+			t.Errorf("%s: Location = %q, want %q", tt.target, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package httperr
+
+import (
+	"context"
+	"net/http"
+)
+
+// SpanRecorder records errors on the trace span of a context. It keeps the
+// package free of a tracing dependency; an OpenTelemetry implementation would
+// call trace.SpanFromContext(ctx).RecordError(err) and
+// SetStatus(codes.Error, msg) respectively.
+type SpanRecorder interface {
+	RecordError(ctx context.Context, err error)
+	SetErrorStatus(ctx context.Context, msg string)
+}
+
+// RecordSpanError returns a [Middleware] that records every error returned by
+// the next [Handler] on the request's span with rec, and marks the span as
+// failed when the status is 500 or above. The error is returned unchanged.
+func RecordSpanError(rec SpanRecorder) Middleware {
+	return Named("httperr.RecordSpanError", func(next Handler) Handler {
+		return HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			err := next.ServeHTTP(w, r)
+			if err == nil {
+				return nil
+			}
+
+			ctx := r.Context()
+			rec.RecordError(ctx, err)
+
+			status, ok := StatusOf(err)
+			if !ok {
+				status = http.StatusInternalServerError
+			}
+			if status >= http.StatusInternalServerError {
+				rec.SetErrorStatus(ctx, http.StatusText(status))
+			}
+
+			return err
+		})
+	})
+}
@@ -0,0 +1,82 @@
+package httperr
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Translator resolves a message key to a message in the language lang,
+// given as a BCP 47 tag such as "en-US". An empty result falls back to the
+// literal message of the error.
+type Translator interface {
+	Translate(lang, key string, args ...any) string
+}
+
+// WithMessageKey sets a key and arguments for a [Translator] to resolve into
+// the client message. The message set with [WithMessage], or the status text,
+// is used when no translator is configured or it has no translation.
+func WithMessageKey(key string, args ...any) Option {
+	return func(h *handlerError) {
+		h.msgKey = key
+		h.msgArgs = args
+	}
+}
+
+// MessageKey returns the key and arguments set with [WithMessageKey].
+func (h *handlerError) MessageKey() (string, []any) {
+	return h.msgKey, h.msgArgs
+}
+
+// WithTranslator translates the client message of errors that carry a
+// message key, using the language preferred by the request's Accept-Language
+// header.
+func WithTranslator(t Translator) HandleOption {
+	return func(eh *errHandler) {
+		eh.translator = t
+	}
+}
+
+func (eh *errHandler) translate(r *http.Request, err error) (string, bool) {
+	if eh.translator == nil {
+		return "", false
+	}
+
+	var keyed interface{ MessageKey() (string, []any) }
+	if !errors.As(err, &keyed) {
+		return "", false
+	}
+
+	key, args := keyed.MessageKey()
+	if key == "" {
+		return "", false
+	}
+
+	msg := eh.translator.Translate(preferredLanguage(r.Header.Get("Accept-Language")), key, args...)
+	return msg, msg != ""
+}
+
+// preferredLanguage returns the language tag with the highest quality in an
+// Accept-Language header, or an empty string if there is none.
+func preferredLanguage(header string) string {
+	best, bestQ := "", 0.0
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.TrimSpace(tag)
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		if q > bestQ {
+			best, bestQ = tag, q
+		}
+	}
+	return best
+}